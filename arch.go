@@ -0,0 +1,109 @@
+package cask
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An Arch represents the CPU architecture supported by the cask Variant.
+type Arch int
+
+// Different CPU architectures.
+const (
+	// ArchAll represents all architectures. This is the default.
+	ArchAll Arch = iota
+	ArchIntel
+	ArchARM
+)
+
+var archSymbols = [...]string{
+	"all",
+	"intel",
+	"arm",
+}
+
+var archValues = [...]string{
+	"",
+	"x86_64",
+	"arm64",
+}
+
+// archFromSymbol returns the Arch matching the provided symbol name used in
+// the "on_intel"/"on_arm" blocks and the "Hardware::CPU.intel?" conditions.
+// The second value reports whether the architecture was found.
+func archFromSymbol(symbol string) (Arch, bool) {
+	for i, s := range archSymbols {
+		if s == symbol {
+			return Arch(i), true
+		}
+	}
+
+	return ArchAll, false
+}
+
+// archFromBlock returns the Arch matching the provided "on_intel" or "on_arm"
+// block identifier. The second value reports whether the identifier is one of
+// them.
+func archFromBlock(ident string) (Arch, bool) {
+	if !strings.HasPrefix(ident, "on_") {
+		return ArchAll, false
+	}
+
+	arch, ok := archFromSymbol(strings.TrimPrefix(ident, "on_"))
+
+	return arch, ok && arch != ArchAll
+}
+
+// Symbol returns the Arch symbol name: "all", "intel" or "arm".
+func (a Arch) Symbol() string {
+	return archSymbols[a]
+}
+
+// Value returns the Arch value used for the "#{arch}" interpolations matching
+// the Homebrew defaults: "x86_64" or "arm64". For the ArchAll it's an empty
+// string.
+func (a Arch) Value() string {
+	return archValues[a]
+}
+
+// Opposite returns the other specific architecture. For the ArchAll it's
+// ArchAll as well.
+func (a Arch) Opposite() Arch {
+	switch a {
+	case ArchIntel:
+		return ArchARM
+	case ArchARM:
+		return ArchIntel
+	}
+
+	return ArchAll
+}
+
+// Matches checks whether the Arch supports the provided one. The ArchAll
+// matches any architecture.
+func (a Arch) Matches(other Arch) bool {
+	return a == ArchAll || other == ArchAll || a == other
+}
+
+// MarshalText returns the Arch.Symbol, so the Arch is encoded as its symbol
+// name in the JSON, YAML and TOML documents.
+func (a Arch) MarshalText() ([]byte, error) {
+	return []byte(a.Symbol()), nil
+}
+
+// UnmarshalText decodes the Arch from its symbol name.
+func (a *Arch) UnmarshalText(text []byte) error {
+	arch, ok := archFromSymbol(string(text))
+	if !ok {
+		return fmt.Errorf("unknown architecture '%s'", text)
+	}
+	*a = arch
+
+	return nil
+}
+
+// String returns the string representation of the Arch, which is the
+// Arch.Symbol.
+func (a Arch) String() string {
+	return a.Symbol()
+}
@@ -0,0 +1,85 @@
+package cask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchFromSymbol(t *testing.T) {
+	arch, ok := archFromSymbol("intel")
+	assert.True(t, ok)
+	assert.Equal(t, ArchIntel, arch)
+
+	arch, ok = archFromSymbol("arm")
+	assert.True(t, ok)
+	assert.Equal(t, ArchARM, arch)
+
+	arch, ok = archFromSymbol("powerpc")
+	assert.False(t, ok)
+	assert.Equal(t, ArchAll, arch)
+}
+
+func TestArchSymbol(t *testing.T) {
+	assert.Equal(t, "all", ArchAll.Symbol())
+	assert.Equal(t, "intel", ArchIntel.Symbol())
+	assert.Equal(t, "arm", ArchARM.Symbol())
+}
+
+func TestArchValue(t *testing.T) {
+	assert.Equal(t, "", ArchAll.Value())
+	assert.Equal(t, "x86_64", ArchIntel.Value())
+	assert.Equal(t, "arm64", ArchARM.Value())
+}
+
+func TestArchOpposite(t *testing.T) {
+	assert.Equal(t, ArchAll, ArchAll.Opposite())
+	assert.Equal(t, ArchARM, ArchIntel.Opposite())
+	assert.Equal(t, ArchIntel, ArchARM.Opposite())
+}
+
+func TestArchMatches(t *testing.T) {
+	assert.True(t, ArchAll.Matches(ArchIntel))
+	assert.True(t, ArchIntel.Matches(ArchAll))
+	assert.True(t, ArchARM.Matches(ArchARM))
+	assert.False(t, ArchIntel.Matches(ArchARM))
+	assert.False(t, ArchARM.Matches(ArchIntel))
+}
+
+func TestArchString(t *testing.T) {
+	assert.Equal(t, "all", ArchAll.String())
+	assert.Equal(t, "intel", ArchIntel.String())
+	assert.Equal(t, "arm", ArchARM.String())
+}
+
+func TestArchFromBlock(t *testing.T) {
+	arch, ok := archFromBlock("on_intel")
+	assert.True(t, ok)
+	assert.Equal(t, ArchIntel, arch)
+
+	arch, ok = archFromBlock("on_arm")
+	assert.True(t, ok)
+	assert.Equal(t, ArchARM, arch)
+
+	_, ok = archFromBlock("on_all")
+	assert.False(t, ok)
+
+	_, ok = archFromBlock("intel")
+	assert.False(t, ok)
+}
+
+func TestArchMarshalText(t *testing.T) {
+	text, err := ArchARM.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "arm", string(text))
+}
+
+func TestArchUnmarshalText(t *testing.T) {
+	var arch Arch
+
+	assert.Nil(t, arch.UnmarshalText([]byte("intel")))
+	assert.Equal(t, ArchIntel, arch)
+
+	assert.EqualError(t, arch.UnmarshalText([]byte("powerpc")), "unknown architecture 'powerpc'")
+	assert.Equal(t, ArchIntel, arch)
+}
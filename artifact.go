@@ -40,6 +40,18 @@ func NewArtifact(t ArtifactType, value string) *Artifact {
 	return &Artifact{t, value, "", false}
 }
 
+// artifactTypeFromName returns the ArtifactType matching the provided stanza
+// name. The second value reports whether the type was found.
+func artifactTypeFromName(name string) (ArtifactType, bool) {
+	for i, n := range artifactTypeNames {
+		if n == name {
+			return ArtifactType(i), true
+		}
+	}
+
+	return ArtifactApp, false
+}
+
 // String returns the string representation of the ArtifactType.
 func (t ArtifactType) String() string {
 	return artifactTypeNames[t]
@@ -1,5 +1,10 @@
 package cask
 
+import (
+	"fmt"
+	"strings"
+)
+
 // A Cask represents the cask used in Homebrew-Cask.
 type Cask struct {
 	// Token specifies the cask token.
@@ -12,17 +17,25 @@ type Cask struct {
 	// pointers.
 	Variants []*Variant
 
+	// Lifecycle specifies the cask deprecation and disabling.
+	Lifecycle Lifecycle
+
+	// Warnings specify all non-fatal problems found during parsing, like
+	// unknown stanzas in the ModeLenient.
+	Warnings []error
+
 	// parser specifies the Parser to be used for parsing the cask.
 	parser *Parser
 }
 
-// NewCask creates a new Cask instance and returns its pointer.
-func NewCask(content string) *Cask {
+// NewCask creates a new Cask instance and returns its pointer. Optionally, the
+// Option functions can be passed to configure the Parser.
+func NewCask(content string, opts ...Option) *Cask {
 	c := new(Cask)
 	c.Content = content
 
 	l := NewLexer(c.Content)
-	c.parser = NewParser(l)
+	c.parser = NewParser(l, opts...)
 	c.parser.cask = c
 
 	return c
@@ -38,6 +51,79 @@ func (c *Cask) AddVariant(variant *Variant) {
 	c.Variants = append(c.Variants, variant)
 }
 
+// VariantFor returns the Cask.Variants item used on the provided macOS release
+// and CPU architecture with all interpolations resolved. Like in Ruby, the
+// first "if" or "elsif" branch supporting them wins, while the "else" branch
+// and the unconditional stanzas are used on all releases not taken by the
+// earlier branches. The ArchAll matches the variants for any architecture.
+// Unless specified during parsing, the "#{arch}" interpolations are resolved
+// using the Arch.Value of the Variant or the provided one. The returned
+// Variant is a copy, so changing it doesn't affect the Cask.
+//
+// An error listing the available variants is returned if no variant matches
+// or if the ArchAll is provided and the variants differ between the
+// architectures.
+func (c *Cask) VariantFor(mac MacOS, arch Arch) (*Variant, error) {
+	i := c.variantIndex(mac, arch)
+	if i < 0 {
+		return nil, fmt.Errorf(
+			"cask '%s' has no variant for %s on %s, available: %s",
+			c.Token,
+			mac.String(),
+			arch.String(),
+			describeVariants(c.Variants),
+		)
+	}
+
+	if arch == ArchAll {
+		intel, arm := c.variantIndex(mac, ArchIntel), c.variantIndex(mac, ArchARM)
+		if intel != arm && intel >= 0 && arm >= 0 {
+			return nil, fmt.Errorf(
+				"cask '%s' has multiple variants for %s on %s: %s",
+				c.Token,
+				mac.String(),
+				arch.String(),
+				describeVariants([]*Variant{c.Variants[intel], c.Variants[arm]}),
+			)
+		}
+	}
+
+	return c.Variants[i].resolved(arch), nil
+}
+
+// variantIndex returns the index of the Cask.Variants item used on the
+// provided macOS release and CPU architecture: the first one supporting both.
+// The releases not supported by any of them use the first variant supporting
+// only the latest targeted release, which is the "else" branch or the
+// unconditional stanzas. Returns -1 if there is no such variant.
+func (c *Cask) variantIndex(mac MacOS, arch Arch) int {
+	for i, v := range c.Variants {
+		if v.supports(mac) && v.Arch.Matches(arch) {
+			return i
+		}
+	}
+
+	options := c.parseOptions()
+	for i, v := range c.Variants {
+		if isLatestVariant(v, options) && v.Arch.Matches(arch) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// describeVariants returns the comma-separated supported macOS ranges and CPU
+// architectures of the provided variants.
+func describeVariants(variants []*Variant) string {
+	ranges := make([]string, len(variants))
+	for i, v := range variants {
+		ranges[i] = v.label()
+	}
+
+	return strings.Join(ranges, ", ")
+}
+
 // String returns a string representation of the Cask struct which is the cask
 // Token.
 func (c Cask) String() string {
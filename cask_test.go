@@ -51,21 +51,6 @@ func TestNewCask(t *testing.T) {
 
 func TestParse(t *testing.T) {
 	testCases := map[string]Cask{
-		"empty.rb": {
-			Token:   "empty",
-			Content: string(getTestdata("empty.rb")),
-			Variants: []*Variant{
-				{
-					Version:   nil,
-					SHA256:    nil,
-					URL:       nil,
-					Appcast:   nil,
-					Names:     nil,
-					Homepage:  nil,
-					Artifacts: nil,
-				},
-			},
-		},
 		"if-global-sha256-last.rb": {
 			Token:   "if-global-sha256-last",
 			Content: string(getTestdata("if-global-sha256-last.rb")),
@@ -1156,6 +1141,53 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseErrors(t *testing.T) {
+	testCases := map[string]string{
+		"":                              "cask block not found",
+		"# just comment":                "cask block not found",
+		"# just comment\n":              "cask block not found",
+		"version '1.0.0'":               "cask block not found",
+		string(getTestdata("empty.rb")): "cask 'empty' has no supported stanzas",
+		"cask 'example' do\n  auto_updates true\nend\n":      "cask 'example' has no supported stanzas",
+		"cask 'example' do\n  version '1.0.0\nend\n":         "Unterminated string at 28",
+		"cask 'example' do\n  caveats <<~EOS\n  text\nend\n": "Unterminated heredoc at 31",
+	}
+
+	for content, expected := range testCases {
+		// preparations
+		c := NewCask(content)
+
+		// test
+		err := c.Parse()
+		assert.Error(t, err, content)
+		assert.IsType(t, &Errors{}, err)
+		assert.Contains(t, err.Error(), expected, content)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	files, err := ioutil.ReadDir(filepath.Join(getWorkingDir(), testdataPath))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, file := range files {
+		if !file.IsDir() {
+			f.Add(string(getTestdata(file.Name())))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		c := NewCask(content)
+		err := c.Parse()
+
+		if err == nil {
+			assert.NotEmpty(t, c.Token)
+			assert.NotEmpty(t, c.Variants)
+		}
+	})
+}
+
 func TestAddVariant(t *testing.T) {
 	// preparations
 	c := NewCask("")
@@ -1166,6 +1198,104 @@ func TestAddVariant(t *testing.T) {
 	assert.Len(t, c.Variants, 1)
 }
 
+func TestVariantFor(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("on-arch.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	v, err := c.VariantFor(MacOSHighSierra, ArchIntel)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0", v.GetVersion().Value)
+	assert.Equal(t, "https://example.com/app_1.0.0_x86_64.dmg", v.URL.Value)
+	assert.Equal(t, "https://example.com/app_#{version}_#{arch}.dmg", c.Variants[0].URL.Value)
+
+	v, err = c.VariantFor(MacOSHighSierra, ArchARM)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/app_2.0.0_arm64.dmg", v.URL.Value)
+
+	// test (macOS ranges)
+	c = NewCask(string(getTestdata("if-global-version-last.rb")))
+	assert.Nil(t, c.Parse())
+
+	testCases := map[MacOS]string{
+		MacOSHighSierra: "https://example.com/app_2.0.0_mac64.dmg",
+		MacOSSierra:     "https://example.com/app_2.0.0_mac64.dmg",
+		MacOSLeopard:    "https://example.com/app_2.0.0_mac32.dmg",
+		MacOSTiger:      "https://example.com/app_2.0.0_mac32.dmg",
+	}
+
+	for mac, expected := range testCases {
+		v, err := c.VariantFor(mac, ArchIntel)
+		assert.Nil(t, err, mac.String())
+		assert.Equal(t, expected, v.URL.Value, mac.String())
+		assert.Equal(t, "/Applications/Example.app/Contents/MacOS/example-if", v.Artifacts[1].Value)
+	}
+
+	// test (branch order)
+	c = NewCask(string(getTestdata("if-three-versions-one-appcast.rb")))
+	assert.Nil(t, c.Parse())
+
+	versions := map[MacOS]string{
+		MacOSHighSierra:  "2.0.0",
+		MacOSSierra:      "2.0.0",
+		MacOSSnowLeopard: "2.0.0",
+		MacOSLeopard:     "1.0.0",
+		MacOSTiger:       "0.9.0",
+	}
+
+	for mac, expected := range versions {
+		v, err := c.VariantFor(mac, ArchAll)
+		assert.Nil(t, err, mac.String())
+		assert.Equal(t, expected, v.GetVersion().Value, mac.String())
+	}
+
+	// test (architectures on older releases)
+	c = NewCask(string(getTestdata("on-arch.rb")))
+	assert.Nil(t, c.Parse())
+
+	v, err = c.VariantFor(MacOSSierra, ArchIntel)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0", v.GetVersion().Value)
+
+	v, err = c.VariantFor(MacOSTiger, ArchARM)
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0.0", v.GetVersion().Value)
+
+	// test (conditions without supported stanzas)
+	c = NewCask(string(getTestdata("unknown-stanzas.rb")))
+	assert.Nil(t, c.Parse())
+
+	for _, mac := range []MacOS{MacOSHighSierra, MacOSSierra, MacOSTiger} {
+		v, err := c.VariantFor(mac, ArchAll)
+		assert.Nil(t, err, mac.String())
+		assert.Equal(t, "1.0.0", v.GetVersion().Value, mac.String())
+	}
+	assert.Equal(t, "10.13 (all)", c.Variants[0].label())
+
+	// test (errors)
+	c = NewCask(string(getTestdata("if-global-version-last.rb")))
+	assert.Nil(t, c.Parse())
+	c.Variants = c.Variants[:1]
+
+	_, err = c.VariantFor(MacOSSierra, ArchAll)
+	assert.EqualError(
+		t,
+		err,
+		"cask 'if-global-version-last' has no variant for macOS Sierra (10.12) on all, available: 10.4-10.5 (all)",
+	)
+
+	c = NewCask(string(getTestdata("if-arch.rb")))
+	assert.Nil(t, c.Parse())
+
+	_, err = c.VariantFor(MacOSHighSierra, ArchAll)
+	assert.EqualError(
+		t,
+		err,
+		"cask 'if-arch' has multiple variants for macOS High Sierra (10.13) on all: 10.13 (intel), 10.13 (arm)",
+	)
+}
+
 func TestCaskString(t *testing.T) {
 	// preparations
 	c := NewCask("")
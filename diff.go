@@ -0,0 +1,459 @@
+package cask
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A ChangeKind represents the Change type.
+type ChangeKind int
+
+// Different change kinds.
+const (
+	// ChangeVariantAdded represents a new variant without a matching one in the
+	// old cask.
+	ChangeVariantAdded ChangeKind = iota
+
+	// ChangeVariantRemoved represents a removed variant without a matching one
+	// in the new cask.
+	ChangeVariantRemoved
+
+	// ChangeVersion represents a version bump or downgrade.
+	ChangeVersion
+
+	// ChangeChecksum represents a sha256 change.
+	ChangeChecksum
+
+	// ChangeURL represents a url change on the same host.
+	ChangeURL
+
+	// ChangeURLHost represents a url change to a different host.
+	ChangeURLHost
+
+	// ChangeArtifactAdded represents a new artifact stanza.
+	ChangeArtifactAdded
+
+	// ChangeArtifactRemoved represents a removed artifact stanza.
+	ChangeArtifactRemoved
+
+	// ChangeMacOSRange represents a supported macOS releases range change.
+	ChangeMacOSRange
+
+	// ChangeArch represents a supported CPU architecture change.
+	ChangeArch
+
+	// ChangeStanza represents a change of any other stanza.
+	ChangeStanza
+)
+
+var changeKindNames = [...]string{
+	"variant-added",
+	"variant-removed",
+	"version",
+	"checksum",
+	"url",
+	"url-host",
+	"artifact-added",
+	"artifact-removed",
+	"macos-range",
+	"arch",
+	"stanza",
+}
+
+// diffVariantStanzas specify the stanzas compared per variant. All other
+// stanzas are compared in the whole cask source.
+var diffVariantStanzas = map[string]bool{
+	"version":   true,
+	"sha256":    true,
+	"url":       true,
+	"appcast":   true,
+	"livecheck": true,
+	"name":      true,
+	"homepage":  true,
+}
+
+// A Change represents a single semantic change between two cask revisions.
+type Change struct {
+	// Kind specifies the change type.
+	Kind ChangeKind `json:"kind"`
+
+	// Variant specifies the label of the changed variant in the new cask (or in
+	// the old one if it was removed). For example: "10.13 (all)". It's empty
+	// for the changes of stanzas that are compared in the whole cask.
+	Variant string `json:"variant,omitempty"`
+
+	// Stanza specifies the changed stanza name. For example: "version" or
+	// "app".
+	Stanza string `json:"stanza,omitempty"`
+
+	// Old specifies the value before the change. It's empty if the stanza was
+	// added.
+	Old string `json:"old,omitempty"`
+
+	// New specifies the value after the change. It's empty if the stanza was
+	// removed.
+	New string `json:"new,omitempty"`
+
+	// Artifact specifies the added or removed artifact.
+	Artifact *ResolvedArtifact `json:"artifact,omitempty"`
+}
+
+// Diff returns the semantic changes between the old and the new revisions of
+// the parsed cask. The variants are matched by their supported macOS releases
+// and CPU architecture first and the remaining ones are matched in order, so
+// a changed macOS range is reported as the ChangeMacOSRange. The values are
+// compared with all interpolations resolved.
+//
+// The added variant is compared with the old Variant previously used on its
+// newest supported macOS release, or with an empty one if there was none, so
+// its content is reported as well. The same way, the removed variant is
+// compared with the new Variant now used instead of it.
+//
+// The stanzas that aren't parsed into the Variant (for example, "preflight" or
+// "uninstall") are compared using their normalized source in the whole cask
+// and reported as the ChangeStanza without the Change.Variant.
+func Diff(old, new *Cask) []Change {
+	changes := make([]Change, 0)
+
+	pairs, removed, added := pairVariants(old.Variants, new.Variants)
+	for _, p := range pairs {
+		changes = append(changes, diffVariants(p[1].label(), p[0], p[1])...)
+	}
+
+	for _, v := range removed {
+		changes = append(changes, Change{Kind: ChangeVariantRemoved, Variant: v.label()})
+		if i := new.variantIndex(v.MaximumSupportedMacOS, v.Arch); i >= 0 {
+			changes = append(changes, diffUnpaired(v.label(), v, new.Variants[i])...)
+		}
+	}
+
+	for _, v := range added {
+		changes = append(changes, Change{Kind: ChangeVariantAdded, Variant: v.label()})
+
+		previous := NewVariant()
+		if i := old.variantIndex(v.MaximumSupportedMacOS, v.Arch); i >= 0 {
+			previous = old.Variants[i]
+		}
+		changes = append(changes, diffUnpaired(v.label(), previous, v)...)
+	}
+
+	return append(changes, diffSources(old.Content, new.Content)...)
+}
+
+// pairVariants matches the old and new variants. The variants with the same
+// label are matched first and the remaining ones are matched in order. The
+// unmatched variants are returned as removed and added.
+func pairVariants(old, new []*Variant) (pairs [][2]*Variant, removed, added []*Variant) {
+	matched := make(map[*Variant]bool)
+	for _, o := range old {
+		for _, n := range new {
+			if !matched[n] && o.label() == n.label() {
+				pairs = append(pairs, [2]*Variant{o, n})
+				matched[o], matched[n] = true, true
+				break
+			}
+		}
+	}
+
+	for _, o := range old {
+		if !matched[o] {
+			removed = append(removed, o)
+		}
+	}
+
+	for _, n := range new {
+		if !matched[n] {
+			added = append(added, n)
+		}
+	}
+
+	for len(removed) > 0 && len(added) > 0 {
+		pairs = append(pairs, [2]*Variant{removed[0], added[0]})
+		removed, added = removed[1:], added[1:]
+	}
+
+	return pairs, removed, added
+}
+
+// diffUnpaired returns the changes between the added or removed variant and
+// the one used instead of it with the provided label. The supported macOS
+// releases and CPU architecture changes are skipped, as they are expected.
+func diffUnpaired(label string, oldVariant, newVariant *Variant) (changes []Change) {
+	for _, c := range diffVariants(label, oldVariant, newVariant) {
+		if c.Kind != ChangeMacOSRange && c.Kind != ChangeArch {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes
+}
+
+// diffVariants returns the changes between the matched old and new variants
+// with the provided label.
+func diffVariants(label string, oldVariant, newVariant *Variant) (changes []Change) {
+	oldVariant, newVariant = oldVariant.resolved(ArchAll), newVariant.resolved(ArchAll)
+	old, new := newResolvedVariant("", oldVariant), newResolvedVariant("", newVariant)
+
+	add := func(kind ChangeKind, stanza string, before string, after string) {
+		if before != after {
+			changes = append(changes, Change{Kind: kind, Variant: label, Stanza: stanza, Old: before, New: after})
+		}
+	}
+
+	add(ChangeMacOSRange, "macos", oldVariant.macOSRange(), newVariant.macOSRange())
+	add(ChangeArch, "arch", old.Arch.String(), new.Arch.String())
+	add(ChangeVersion, "version", old.Version, new.Version)
+	add(ChangeChecksum, "sha256", old.SHA256, new.SHA256)
+
+	if urlHost(old.URL) != urlHost(new.URL) {
+		add(ChangeURLHost, "url", old.URL, new.URL)
+	} else {
+		add(ChangeURL, "url", old.URL, new.URL)
+	}
+
+	add(ChangeStanza, "appcast", old.Appcast, new.Appcast)
+	add(ChangeStanza, "livecheck", oldVariant.GetLivecheck().String(), newVariant.GetLivecheck().String())
+	add(ChangeStanza, "name", strings.Join(old.Names, ", "), strings.Join(new.Names, ", "))
+	add(ChangeStanza, "homepage", old.Homepage, new.Homepage)
+
+	oldArtifacts := make(map[ResolvedArtifact]int)
+	for _, a := range old.Artifacts {
+		oldArtifacts[a]++
+	}
+
+	var addedArtifacts []ResolvedArtifact
+	for _, a := range new.Artifacts {
+		if oldArtifacts[a] > 0 {
+			oldArtifacts[a]--
+			continue
+		}
+		addedArtifacts = append(addedArtifacts, a)
+	}
+
+	for _, a := range old.Artifacts {
+		if oldArtifacts[a] > 0 {
+			oldArtifacts[a]--
+			artifact := a
+			changes = append(changes, Change{
+				Kind:     ChangeArtifactRemoved,
+				Variant:  label,
+				Stanza:   a.Type,
+				Old:      a.String(),
+				Artifact: &artifact,
+			})
+		}
+	}
+
+	for i, a := range addedArtifacts {
+		changes = append(changes, Change{
+			Kind:     ChangeArtifactAdded,
+			Variant:  label,
+			Stanza:   a.Type,
+			New:      a.String(),
+			Artifact: &addedArtifacts[i],
+		})
+	}
+
+	return changes
+}
+
+// diffSources returns the changes of the stanzas that aren't compared per
+// variant. The stanzas with the same name are compared together. If any of the
+// sources can't be tokenized, no changes are returned.
+func diffSources(oldContent, newContent string) (changes []Change) {
+	old, err := sourceStanzas(oldContent)
+	if err != nil {
+		return nil
+	}
+
+	new, err := sourceStanzas(newContent)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(old)+len(new))
+	for name := range old {
+		names = append(names, name)
+	}
+
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		before, after := strings.Join(old[name], "\n"), strings.Join(new[name], "\n")
+		if before != after {
+			changes = append(changes, Change{Kind: ChangeStanza, Stanza: name, Old: before, New: after})
+		}
+	}
+
+	return changes
+}
+
+// sourceStanzas returns the normalized sources of the stanzas that aren't
+// compared per variant grouped by the stanza name.
+func sourceStanzas(content string) (map[string][]string, error) {
+	tree, err := NewSyntaxTree(content)
+	if err != nil {
+		return nil, err
+	}
+
+	stanzas := make(map[string][]string)
+	for _, s := range tree.Stanzas {
+		if _, ok := artifactTypeFromName(s.Name); ok || diffVariantStanzas[s.Name] {
+			continue
+		}
+
+		stanzas[s.Name] = append(stanzas[s.Name], normalizedSource(s))
+	}
+
+	return stanzas, nil
+}
+
+// normalizedSource returns the stanza source rebuilt from its tokens, so the
+// comments and formatting don't affect the comparison.
+func normalizedSource(s *StanzaNode) string {
+	var b strings.Builder
+
+	previous := NEWLINE
+	for _, t := range s.Tokens {
+		literal := t.Literal
+		space := true
+
+		switch t.Type {
+		case STRING:
+			literal = strconv.Quote(literal)
+		case SYMBOL:
+			literal = ":" + literal
+			space = t.Literal != ""
+		case NEWLINE:
+			literal = "\n"
+			space = false
+		case COMMA, DOT, SCOPE, RBRACKET, RPAREN:
+			space = false
+		}
+
+		switch previous {
+		case NEWLINE, DOT, SCOPE, LBRACKET, LPAREN:
+			space = false
+		}
+
+		if space {
+			b.WriteString(" ")
+		}
+
+		b.WriteString(literal)
+		previous = t.Type
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// urlHost returns the lowercase host of the provided URL. If the URL can't be
+// parsed, it's returned as is.
+func urlHost(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// String returns the string representation of the ChangeKind.
+func (k ChangeKind) String() string {
+	return changeKindNames[k]
+}
+
+// MarshalText returns the ChangeKind name, so it's encoded as a string in the
+// JSON documents.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes the ChangeKind from its name.
+func (k *ChangeKind) UnmarshalText(text []byte) error {
+	for i, name := range changeKindNames {
+		if name == string(text) {
+			*k = ChangeKind(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown change kind '%s'", text)
+}
+
+// String returns the human-readable description of the Change. For example:
+// "[10.13 (all)] version bump: 1.0.0 -> 2.0.0". The multiline values are
+// omitted.
+func (c Change) String() (result string) {
+	if c.Variant != "" {
+		result = fmt.Sprintf("[%s] ", c.Variant)
+	}
+
+	switch c.Kind {
+	case ChangeVariantAdded:
+		return result + "variant added"
+	case ChangeVariantRemoved:
+		return result + "variant removed"
+	case ChangeVersion:
+		if NewVersion(c.New).LessThan(*NewVersion(c.Old)) {
+			return result + fmt.Sprintf("version downgrade: %s -> %s", c.Old, c.New)
+		}
+		return result + fmt.Sprintf("version bump: %s -> %s", c.Old, c.New)
+	case ChangeURLHost:
+		return result + fmt.Sprintf("url host changed (%s -> %s): %s -> %s", urlHost(c.Old), urlHost(c.New), c.Old, c.New)
+	case ChangeArtifactAdded:
+		return result + fmt.Sprintf("artifact added: %s", c.New)
+	case ChangeArtifactRemoved:
+		return result + fmt.Sprintf("artifact removed: %s", c.Old)
+	case ChangeMacOSRange:
+		return result + fmt.Sprintf("macOS range changed: %s -> %s", c.Old, c.New)
+	}
+
+	switch {
+	case c.Old == "":
+		result += fmt.Sprintf("%s added", c.Stanza)
+		if !strings.Contains(c.New, "\n") {
+			result += ": " + c.New
+		}
+	case c.New == "":
+		result += fmt.Sprintf("%s removed", c.Stanza)
+	case strings.Contains(c.Old, "\n") || strings.Contains(c.New, "\n"):
+		result += fmt.Sprintf("%s changed", c.Stanza)
+	default:
+		result += fmt.Sprintf("%s changed: %s -> %s", c.Stanza, c.Old, c.New)
+	}
+
+	return result
+}
+
+// FormatChanges returns the human-readable representation of the provided
+// changes with each Change on a separate line.
+func FormatChanges(changes []Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// FormatChangesJSON returns the indented JSON encoding of the provided
+// changes.
+func FormatChangesJSON(changes []Change) ([]byte, error) {
+	if changes == nil {
+		changes = []Change{}
+	}
+
+	return json.MarshalIndent(changes, "", "  ")
+}
@@ -0,0 +1,266 @@
+package cask
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// parseTestDiffCasks parses the provided old and new cask contents.
+func parseTestDiffCasks(t *testing.T, old string, new string) (*Cask, *Cask) {
+	o, n := NewCask(old), NewCask(new)
+	assert.Nil(t, o.Parse())
+	assert.Nil(t, n.Parse())
+
+	return o, n
+}
+
+const diffTestOld = `cask 'example' do
+  version '1.0.0'
+  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+  url "https://example.com/app_#{version}.dmg"
+  name 'Example'
+  homepage 'https://example.com/'
+
+  app 'Example.app'
+  binary 'example'
+
+  uninstall quit: 'com.example.app'
+end
+`
+
+const diffTestNew = `cask 'example' do
+  version '2.0.0'
+  sha256 :no_check
+
+  # moved to the CDN
+  url "https://cdn.example.org/app_#{version}.pkg"
+  name 'Example'
+  homepage 'https://example.com/'
+
+  pkg 'app_#{version}.pkg', allow_untrusted: true
+  binary 'example'
+
+  preflight do
+    system_command '/bin/echo', args: ['preflight']
+  end
+
+  uninstall quit: 'com.example.app',
+            script: { executable: 'uninstall.sh', sudo: true }
+end
+`
+
+func TestDiff(t *testing.T) {
+	// preparations
+	old, new := parseTestDiffCasks(t, diffTestOld, diffTestNew)
+
+	// test
+	changes := Diff(old, new)
+	assert.Equal(t, []Change{
+		{Kind: ChangeVersion, Variant: "10.13 (all)", Stanza: "version", Old: "1.0.0", New: "2.0.0"},
+		{
+			Kind:    ChangeChecksum,
+			Variant: "10.13 (all)",
+			Stanza:  "sha256",
+			Old:     "cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435",
+			New:     "no_check",
+		},
+		{
+			Kind:    ChangeURLHost,
+			Variant: "10.13 (all)",
+			Stanza:  "url",
+			Old:     "https://example.com/app_1.0.0.dmg",
+			New:     "https://cdn.example.org/app_2.0.0.pkg",
+		},
+		{
+			Kind:     ChangeArtifactRemoved,
+			Variant:  "10.13 (all)",
+			Stanza:   "app",
+			Old:      "app, Example.app",
+			Artifact: &ResolvedArtifact{Type: "app", Value: "Example.app"},
+		},
+		{
+			Kind:     ChangeArtifactAdded,
+			Variant:  "10.13 (all)",
+			Stanza:   "pkg",
+			New:      "pkg, app_2.0.0.pkg, allow_untrusted: true",
+			Artifact: &ResolvedArtifact{Type: "pkg", Value: "app_2.0.0.pkg", AllowUntrusted: true},
+		},
+		{
+			Kind:   ChangeStanza,
+			Stanza: "preflight",
+			New:    "preflight do\nsystem_command \"/bin/echo\", args: [\"preflight\"]\nend",
+		},
+		{
+			Kind:   ChangeStanza,
+			Stanza: "uninstall",
+			Old:    "uninstall quit: \"com.example.app\"",
+			New:    "uninstall quit: \"com.example.app\",\nscript: { executable: \"uninstall.sh\", sudo: true }",
+		},
+	}, changes)
+
+	// test (no changes)
+	assert.Empty(t, Diff(old, old))
+
+	// test (missing checksum)
+	old, new = parseTestDiffCasks(t, diffTestOld, strings.Replace(diffTestOld, "  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'\n", "", 1))
+
+	changes = Diff(old, new)
+	assert.Equal(t, []Change{
+		{
+			Kind:    ChangeChecksum,
+			Variant: "10.13 (all)",
+			Stanza:  "sha256",
+			Old:     "cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435",
+		},
+	}, changes)
+	assert.Equal(t, "[10.13 (all)] sha256 removed", changes[0].String())
+}
+
+func TestDiffURL(t *testing.T) {
+	// preparations
+	old, new := parseTestDiffCasks(
+		t,
+		"cask 'example' do\n  url 'https://example.com/app.dmg'\nend\n",
+		"cask 'example' do\n  url 'https://EXAMPLE.com/app.zip'\nend\n",
+	)
+
+	// test
+	assert.Equal(t, []Change{
+		{
+			Kind:    ChangeURL,
+			Variant: "10.13 (all)",
+			Stanza:  "url",
+			Old:     "https://example.com/app.dmg",
+			New:     "https://EXAMPLE.com/app.zip",
+		},
+	}, Diff(old, new))
+}
+
+func TestDiffVariants(t *testing.T) {
+	// preparations
+	old, new := parseTestDiffCasks(
+		t,
+		string(getTestdata("if-global-version-last.rb")),
+		`cask 'if-global-version-last' do
+  if MacOS.version <= :sierra
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+    url "https://example.com/app_#{version}_mac32.dmg"
+  else
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+    url "https://example.com/app_#{version}_mac64.dmg"
+  end
+
+  version '2.0.0'
+  appcast "https://example.com/sparkle/#{version.major}/appcast.xml"
+  name 'Example'
+  name 'Example (if-global-version-last)'
+  homepage 'https://example.com/'
+
+  auto_updates true
+
+  app 'Example (if-global-version-last).app', target: 'Example.app'
+  binary "#{appdir}/Example.app/Contents/MacOS/example-if", target: 'example'
+end
+`,
+	)
+
+	// test
+	assert.Equal(t, []Change{
+		{Kind: ChangeMacOSRange, Variant: "10.4-10.12 (all)", Stanza: "macos", Old: "10.4-10.5", New: "10.4-10.12"},
+	}, Diff(old, new))
+
+	// test (added and removed variants)
+	single := NewCask(string(getTestdata("example-one.rb")))
+	assert.Nil(t, single.Parse())
+
+	changes := Diff(single, old)
+	assert.Contains(t, changes, Change{Kind: ChangeVariantAdded, Variant: "10.4-10.5 (all)"})
+
+	changes = Diff(old, single)
+	assert.Contains(t, changes, Change{Kind: ChangeVariantRemoved, Variant: "10.4-10.5 (all)"})
+}
+
+func TestChangeKindText(t *testing.T) {
+	for i, name := range changeKindNames {
+		text, err := ChangeKind(i).MarshalText()
+		assert.Nil(t, err)
+		assert.Equal(t, name, string(text))
+
+		var kind ChangeKind
+		assert.Nil(t, kind.UnmarshalText(text))
+		assert.Equal(t, ChangeKind(i), kind)
+	}
+
+	var kind ChangeKind
+	assert.EqualError(t, kind.UnmarshalText([]byte("unknown")), "unknown change kind 'unknown'")
+}
+
+func TestChangeString(t *testing.T) {
+	testCases := map[string]Change{
+		"[10.13 (all)] variant added":                   {Kind: ChangeVariantAdded, Variant: "10.13 (all)"},
+		"[10.13 (all)] variant removed":                 {Kind: ChangeVariantRemoved, Variant: "10.13 (all)"},
+		"[10.13 (all)] version bump: 1.0.0 -> 1.10.0":   {Kind: ChangeVersion, Variant: "10.13 (all)", Old: "1.0.0", New: "1.10.0"},
+		"[10.13 (all)] version downgrade: 2.0 -> 1.9.9": {Kind: ChangeVersion, Variant: "10.13 (all)", Old: "2.0", New: "1.9.9"},
+		"[10.13 (all)] sha256 changed: a -> no_check":   {Kind: ChangeChecksum, Variant: "10.13 (all)", Stanza: "sha256", Old: "a", New: "no_check"},
+		"url host changed (a.com -> b.org): https://a.com/x -> https://b.org/x": {
+			Kind: ChangeURLHost, Stanza: "url", Old: "https://a.com/x", New: "https://b.org/x",
+		},
+		"artifact added: app, Example.app":             {Kind: ChangeArtifactAdded, Stanza: "app", New: "app, Example.app"},
+		"artifact removed: app, Example.app":           {Kind: ChangeArtifactRemoved, Stanza: "app", Old: "app, Example.app"},
+		"macOS range changed: 10.13 -> 10.12-10.13":    {Kind: ChangeMacOSRange, Stanza: "macos", Old: "10.13", New: "10.12-10.13"},
+		"auto_updates added: auto_updates true":        {Kind: ChangeStanza, Stanza: "auto_updates", New: "auto_updates true"},
+		"auto_updates removed":                         {Kind: ChangeStanza, Stanza: "auto_updates", Old: "auto_updates true"},
+		"preflight added":                              {Kind: ChangeStanza, Stanza: "preflight", New: "preflight do\nend"},
+		"preflight changed":                            {Kind: ChangeStanza, Stanza: "preflight", Old: "preflight do\nend", New: "preflight do\nx\nend"},
+		"homepage changed: https://a.com -> https://b": {Kind: ChangeStanza, Stanza: "homepage", Old: "https://a.com", New: "https://b"},
+	}
+
+	for expected, c := range testCases {
+		assert.Equal(t, expected, c.String())
+	}
+}
+
+func TestFormatChanges(t *testing.T) {
+	// preparations
+	old, new := parseTestDiffCasks(t, diffTestOld, diffTestNew)
+
+	// test
+	assert.Equal(t, `[10.13 (all)] version bump: 1.0.0 -> 2.0.0
+[10.13 (all)] sha256 changed: cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435 -> no_check
+[10.13 (all)] url host changed (example.com -> cdn.example.org): https://example.com/app_1.0.0.dmg -> https://cdn.example.org/app_2.0.0.pkg
+[10.13 (all)] artifact removed: app, Example.app
+[10.13 (all)] artifact added: pkg, app_2.0.0.pkg, allow_untrusted: true
+preflight added
+uninstall changed
+`, FormatChanges(Diff(old, new)))
+
+	assert.Equal(t, "", FormatChanges(nil))
+}
+
+func TestFormatChangesJSON(t *testing.T) {
+	// preparations
+	old, new := parseTestDiffCasks(t, diffTestOld, diffTestNew)
+	changes := Diff(old, new)
+
+	// test
+	data, err := FormatChangesJSON(changes[:1])
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[
+		{"kind": "version", "variant": "10.13 (all)", "stanza": "version", "old": "1.0.0", "new": "2.0.0"}
+	]`, string(data))
+
+	data, err = FormatChangesJSON(changes)
+	assert.Nil(t, err)
+
+	var decoded []Change
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, changes, decoded)
+
+	data, err = FormatChangesJSON(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "[]", string(data))
+}
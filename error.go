@@ -24,6 +24,45 @@ type unexpectedTokenError struct {
 	actualToken TokenType
 }
 
+// An InterpolationError represents the error that occurs when the Ruby string
+// interpolation can't be resolved.
+type InterpolationError struct {
+	// Expression specifies the unresolved interpolation including the
+	// surrounding "#{" and "}".
+	Expression string
+
+	// Reason specifies why the interpolation can't be resolved.
+	Reason string
+}
+
+// An UnknownStanzaError represents the error that occurs when the stanza found
+// inside the cask block isn't recognised by the Parser. Depending on the
+// Parser mode, it's reported either as an error or as a warning.
+type UnknownStanzaError struct {
+	// Stanza specifies the unknown stanza name.
+	Stanza string
+
+	// Position specifies the stanza position in the cask content.
+	Position Position
+}
+
+// A ValidationError represents a single problem found by the Cask.Validate
+// or the Variant.Validate.
+type ValidationError struct {
+	// Variant specifies the Cask.Variants index of the invalid Variant. It's -1
+	// for the problems of the whole cask and for the ones found by the
+	// Variant.Validate.
+	Variant int
+
+	// Field specifies the invalid field name: "token", "variants", "version",
+	// "sha256", "url", "appcast", "livecheck", "names", "homepage",
+	// "artifacts[i]" or "macos".
+	Field string
+
+	// Message specifies the human-readable problem description.
+	Message string
+}
+
 // NewErrors creates a new Errors instance and returns its pointer. Requires
 // both Errors.context and Errors.errors to be passed as arguments.
 func NewErrors(context string, errors ...error) *Errors {
@@ -51,3 +90,22 @@ func (u *unexpectedTokenError) Error() string {
 		u.actualToken.String(),
 	)
 }
+
+// Error returns a string representation of the UnknownStanzaError.
+func (u *UnknownStanzaError) Error() string {
+	return fmt.Sprintf("%s: unknown stanza '%s'", u.Position, u.Stanza)
+}
+
+// Error returns a string representation of the InterpolationError.
+func (i *InterpolationError) Error() string {
+	return fmt.Sprintf("unresolved interpolation '%s': %s", i.Expression, i.Reason)
+}
+
+// Error returns a string representation of the ValidationError.
+func (v ValidationError) Error() string {
+	if v.Variant < 0 {
+		return fmt.Sprintf("%s: %s", v.Field, v.Message)
+	}
+
+	return fmt.Sprintf("variant %d: %s: %s", v.Variant, v.Field, v.Message)
+}
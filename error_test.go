@@ -36,3 +36,31 @@ func TestUnexpectedTokenErrorError(t *testing.T) {
 	// test
 	assert.Equal(t, "expected next token to be of type [CONST], got GLOBAL instead", e.Error())
 }
+
+func TestUnknownStanzaErrorError(t *testing.T) {
+	// preparations
+	e := &UnknownStanzaError{
+		Stanza:   "hompage",
+		Position: Position{20, 2, 3},
+	}
+
+	// test
+	assert.Equal(t, "2:3: unknown stanza 'hompage'", e.Error())
+}
+
+func TestInterpolationErrorError(t *testing.T) {
+	// preparations
+	e := &InterpolationError{
+		Expression: "#{arch}",
+		Reason:     "variable 'arch' is not set",
+	}
+
+	// test
+	assert.Equal(t, "unresolved interpolation '#{arch}': variable 'arch' is not set", e.Error())
+}
+
+func TestValidationErrorError(t *testing.T) {
+	// test
+	assert.Equal(t, "token: cask token is empty", ValidationError{-1, "token", "cask token is empty"}.Error())
+	assert.Equal(t, "variant 1: url: missing required stanza", ValidationError{1, "url", "missing required stanza"}.Error())
+}
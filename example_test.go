@@ -52,7 +52,7 @@ func Example_one() {
 	//   homepage: https://example.com/
 	//  artifacts: app, Example 2.0.app => Example.app
 	//             app, Example 2.0 Uninstaller.app
-	//             binary, #{appdir}/Example 2.0.app/Contents/MacOS/example-one => example
+	//             binary, /Applications/Example 2.0.app/Contents/MacOS/example-one => example
 	//      macOS: macOS High Sierra (10.13) [minimum]
 	//             macOS High Sierra (10.13) [maximum]
 }
@@ -119,3 +119,29 @@ func Example_two() {
 	//      macOS: macOS High Sierra (10.13) [minimum]
 	//             macOS High Sierra (10.13) [maximum]
 }
+
+func Example_resolve() {
+	// for this example we will load the cask from our testdata directory
+	content := string(getTestdata("example-two.rb"))
+
+	// example
+	c := NewCask(content)
+	err := c.Parse()
+
+	if err == nil {
+		for _, v := range c.Resolve() {
+			fmt.Printf(
+				"%s %s (macOS %s-%s): %s\n",
+				v.Token,
+				v.Version,
+				v.MinimumMacOSVersion,
+				v.MaximumMacOSVersion,
+				v.URL,
+			)
+		}
+	}
+
+	// Output:
+	// example-two 1.5.0 (macOS 10.4-10.11): https://example.com/app_1.5.0.pkg
+	// example-two 2.0.0 (macOS 10.13-10.13): https://example.com/app_2.0.0.pkg
+}
@@ -0,0 +1,66 @@
+// Package export encodes the parsed casks as the YAML and TOML documents and
+// decodes them back. The documents use the cask.Schema, which is shared with
+// the JSON encoding, so all formats have the same stable field names and
+// include all variants with their supported macOS releases.
+package export
+
+import (
+	"bytes"
+
+	"github.com/BurntSushi/toml"
+	"github.com/victorpopkov/go-cask"
+	"gopkg.in/yaml.v3"
+)
+
+// YAML returns the YAML document of the provided Cask indented with two
+// spaces.
+func YAML(c *cask.Cask) ([]byte, error) {
+	var buf bytes.Buffer
+
+	e := yaml.NewEncoder(&buf)
+	e.SetIndent(2)
+
+	if err := e.Encode(cask.NewSchema(c)); err != nil {
+		return nil, err
+	}
+
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FromYAML decodes the Cask from the provided YAML document. Returns an error
+// if the document is invalid or has values that can't be represented, like an
+// unknown macOS release.
+func FromYAML(data []byte) (*cask.Cask, error) {
+	var s cask.Schema
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return s.Cask()
+}
+
+// TOML returns the TOML document of the provided Cask.
+func TOML(c *cask.Cask) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cask.NewSchema(c)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FromTOML decodes the Cask from the provided TOML document. Returns an error
+// if the document is invalid or has values that can't be represented, like an
+// unknown macOS release.
+func FromTOML(data []byte) (*cask.Cask, error) {
+	var s cask.Schema
+	if _, err := toml.Decode(string(data), &s); err != nil {
+		return nil, err
+	}
+
+	return s.Cask()
+}
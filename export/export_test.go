@@ -0,0 +1,150 @@
+package export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/victorpopkov/go-cask"
+)
+
+func getTestdata(filename string) string {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	return string(content)
+}
+
+func parseTestCask(t *testing.T, content string) *cask.Cask {
+	c := cask.NewCask(content)
+	assert.Nil(t, c.Parse())
+
+	return c
+}
+
+func TestYAML(t *testing.T) {
+	// preparations
+	c := parseTestCask(t, getTestdata("example.rb"))
+
+	// test
+	actual, err := YAML(c)
+	assert.Nil(t, err)
+	assert.Equal(t, getTestdata("example.yml"), string(actual))
+}
+
+func TestFromYAML(t *testing.T) {
+	// preparations
+	expected := parseTestCask(t, getTestdata("example.rb"))
+
+	// test
+	actual, err := FromYAML([]byte(getTestdata("example.yml")))
+	assert.Nil(t, err)
+	assert.Equal(t, cask.NewSchema(expected), cask.NewSchema(actual))
+	assert.Equal(t, expected.Lifecycle, actual.Lifecycle)
+	assert.Equal(t, cask.MacOSTiger, actual.Variants[0].MinimumSupportedMacOS)
+	assert.Equal(t, cask.MacOSSierra, actual.Variants[0].MaximumSupportedMacOS)
+
+	// test (errors)
+	_, err = FromYAML([]byte("variants: ["))
+	assert.Error(t, err)
+
+	_, err = FromYAML([]byte("variants:\n  - minimum_macos: unknown\n"))
+	assert.EqualError(t, err, "variant 0: unknown macOS release 'unknown'")
+
+	_, err = FromYAML([]byte("variants:\n  - artifacts:\n      - type: zap\n"))
+	assert.EqualError(t, err, "variant 0: unsupported artifact type 'zap'")
+}
+
+func TestTOML(t *testing.T) {
+	// preparations
+	c := parseTestCask(t, getTestdata("example.rb"))
+
+	// test
+	actual, err := TOML(c)
+	assert.Nil(t, err)
+	assert.Equal(t, getTestdata("example.toml"), string(actual))
+}
+
+func TestFromTOML(t *testing.T) {
+	// preparations
+	expected := parseTestCask(t, getTestdata("example.rb"))
+
+	// test
+	actual, err := FromTOML([]byte(getTestdata("example.toml")))
+	assert.Nil(t, err)
+	assert.Equal(t, cask.NewSchema(expected), cask.NewSchema(actual))
+	assert.Equal(t, expected.Lifecycle, actual.Lifecycle)
+
+	// test (errors)
+	_, err = FromTOML([]byte("token = "))
+	assert.Error(t, err)
+
+	_, err = FromTOML([]byte("[deprecated]\ndate = \"2023\"\n"))
+	assert.EqualError(t, err, "lifecycle date '2023' is invalid")
+}
+
+func TestSchemaFormats(t *testing.T) {
+	// preparations
+	c := parseTestCask(t, getTestdata("example.rb"))
+
+	yml, err := YAML(c)
+	assert.Nil(t, err)
+
+	tml, err := TOML(c)
+	assert.Nil(t, err)
+
+	// test
+	fromYAML, err := FromYAML(yml)
+	assert.Nil(t, err)
+
+	fromTOML, err := FromTOML(tml)
+	assert.Nil(t, err)
+
+	assert.Equal(t, cask.NewSchema(fromYAML), cask.NewSchema(fromTOML))
+
+	// test (architectures)
+	c = parseTestCask(t, getTestdata("on-arch.rb"))
+
+	yml, err = YAML(c)
+	assert.Nil(t, err)
+	assert.Contains(t, string(yml), "url: https://example.com/app_1.0.0_x86_64.dmg")
+	assert.Contains(t, string(yml), "url: https://example.com/app_2.0.0_arm64.dmg")
+	assert.NotContains(t, string(yml), "#{arch}")
+
+	tml, err = TOML(c)
+	assert.Nil(t, err)
+	assert.Contains(t, string(tml), `url = "https://example.com/app_1.0.0_x86_64.dmg"`)
+	assert.Contains(t, string(tml), `url = "https://example.com/app_2.0.0_arm64.dmg"`)
+	assert.NotContains(t, string(tml), "#{arch}")
+
+	fromYAML, err = FromYAML(yml)
+	assert.Nil(t, err)
+	assert.Equal(t, cask.ArchIntel, fromYAML.Variants[0].Arch)
+	assert.Equal(t, cask.ArchARM, fromYAML.Variants[1].Arch)
+	assert.Equal(t, cask.NewSchema(c), cask.NewSchema(fromYAML))
+
+	// test (missing checksum)
+	c = parseTestCask(t, "cask 'example' do\n  version '1.0.0'\nend\n")
+
+	yml, err = YAML(c)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(yml), "sha256")
+
+	tml, err = TOML(c)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(tml), "sha256")
+
+	fromYAML, err = FromYAML(yml)
+	assert.Nil(t, err)
+	assert.Nil(t, fromYAML.Variants[0].SHA256)
+
+	fromTOML, err = FromTOML(tml)
+	assert.Nil(t, err)
+	assert.Nil(t, fromTOML.Variants[0].SHA256)
+}
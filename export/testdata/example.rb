@@ -0,0 +1,23 @@
+cask 'example' do
+  if MacOS.version <= :sierra
+    version '1.0.0'
+    sha256 '92521fc3cbd964bdc9f584a991b89fddaa5754ed1cc96d6d42445338669c1305'
+  else
+    version '2.0.0'
+    sha256 'f22abd6773ab232869321ad4b1e47ac0c908febf4f3a2bd10c8066140f741261'
+  end
+
+  url "https://example.com/app_#{version}.dmg"
+  name 'Example'
+  homepage 'https://example.com/'
+
+  livecheck do
+    url :homepage
+    regex(/app_(\d+(?:\.\d+)+)\.dmg/i)
+  end
+
+  deprecate! date: '2023-01-01', because: :discontinued
+
+  pkg 'Example.pkg', allow_untrusted: true
+  binary "#{appdir}/Example.app/Contents/MacOS/example", target: 'example'
+end
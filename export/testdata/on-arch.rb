@@ -0,0 +1,16 @@
+cask 'on-arch' do
+  on_intel do
+    version '1.0.0'
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+  end
+  on_arm do
+    version '2.0.0'
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+  end
+
+  url "https://example.com/app_#{version}_#{arch}.dmg"
+  name 'Example'
+  homepage 'https://example.com/'
+
+  app 'Example.app'
+end
@@ -0,0 +1,672 @@
+package cask
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// An Interpolator represents the engine that resolves the Ruby string
+// interpolations ("#{...}") used in the stanza values.
+type Interpolator struct {
+	// Version specifies the Version used to resolve the "#{version}"
+	// interpolations including the version methods.
+	Version *Version
+
+	// Variables specify all other interpolation variables by name. For
+	// example: "appdir", "token", "language", "arch" or constants like
+	// "HOMEPAGE".
+	Variables map[string]string
+}
+
+// Different interpolation variable names with special meaning.
+const (
+	VariableAppdir       = "appdir"
+	VariableArch         = "arch"
+	VariableCaskroomPath = "caskroom_path"
+	VariableLanguage     = "language"
+	VariableStagedPath   = "staged_path"
+	VariableToken        = "token"
+	VariableVersion      = "version"
+)
+
+// DefaultInterpolationVariables returns the default values for the path
+// interpolation variables matching the Homebrew-Cask defaults. The
+// "staged_path" variable isn't included since it's derived from the
+// "caskroom_path", "token" and "version" unless specified explicitly.
+func DefaultInterpolationVariables() map[string]string {
+	return map[string]string{
+		VariableAppdir:       "/Applications",
+		VariableCaskroomPath: "/usr/local/Caskroom",
+	}
+}
+
+// NewInterpolator creates a new Interpolator instance and returns its pointer.
+// Requires both the Interpolator.Version and Interpolator.Variables to be
+// passed as arguments. Both can be nil.
+func NewInterpolator(version *Version, variables map[string]string) *Interpolator {
+	return &Interpolator{
+		Version:   version,
+		Variables: variables,
+	}
+}
+
+// HasInterpolation checks whether the provided string has at least one Ruby
+// string interpolation.
+func (i *Interpolator) HasInterpolation(str string) bool {
+	return len(findInterpolations(str)) > 0
+}
+
+// Interpolate resolves all Ruby string interpolations in the provided string
+// and returns the result. The interpolations that can't be resolved are left
+// as is and reported as InterpolationError errors.
+func (i *Interpolator) Interpolate(str string) (string, []error) {
+	var errs []error
+	var result strings.Builder
+
+	last := 0
+	for _, loc := range findInterpolations(str) {
+		result.WriteString(str[last:loc[0]])
+		last = loc[1]
+
+		value, err := i.evaluate(str[loc[0]+2 : loc[1]-1])
+		if err != nil {
+			errs = append(errs, &InterpolationError{
+				Expression: str[loc[0]:loc[1]],
+				Reason:     err.Error(),
+			})
+			result.WriteString(str[loc[0]:loc[1]])
+			continue
+		}
+
+		result.WriteString(value)
+	}
+	result.WriteString(str[last:])
+
+	return result.String(), errs
+}
+
+// evaluate evaluates a single interpolation expression (without the
+// surrounding "#{" and "}") and returns its value.
+func (i *Interpolator) evaluate(expression string) (string, error) {
+	receiver, calls, err := parseInterpolationExpression(expression)
+	if err != nil {
+		return "", err
+	}
+
+	var value interpolationValue
+	if receiver == VariableVersion {
+		if i.Version == nil {
+			return "", fmt.Errorf("variable '%s' is not set", receiver)
+		}
+		value = interpolationValue{str: i.Version.Value, isVersion: true}
+	} else {
+		str, err := i.variable(receiver)
+		if err != nil {
+			return "", err
+		}
+		value = interpolationValue{str: str}
+	}
+
+	for _, c := range calls {
+		value, err = value.call(receiver, c)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if value.isList {
+		return "", fmt.Errorf("expression '%s' evaluates to a list", strings.TrimSpace(expression))
+	}
+
+	return value.str, nil
+}
+
+// variable returns the value of the interpolation variable with the specified
+// name.
+func (i *Interpolator) variable(name string) (string, error) {
+	if value, ok := i.Variables[name]; ok {
+		return value, nil
+	}
+
+	if name == VariableStagedPath {
+		caskroom, err := i.variable(VariableCaskroomPath)
+		if err != nil {
+			return "", err
+		}
+
+		token, err := i.variable(VariableToken)
+		if err != nil {
+			return "", err
+		}
+
+		if i.Version == nil {
+			return "", fmt.Errorf("variable '%s' is not set", VariableVersion)
+		}
+
+		return path.Join(caskroom, token, i.Version.Value), nil
+	}
+
+	return "", fmt.Errorf("variable '%s' is not set", name)
+}
+
+// findInterpolations returns the start and end positions of all Ruby string
+// interpolations in the provided string. Nested braces and quoted strings
+// inside the interpolation are respected.
+func findInterpolations(str string) (locations [][2]int) {
+	for start := 0; start < len(str)-1; start++ {
+		if str[start] != '#' || str[start+1] != '{' {
+			continue
+		}
+
+		end := matchingBrace(str, start+1)
+		if end < 0 {
+			break
+		}
+
+		locations = append(locations, [2]int{start, end + 1})
+		start = end
+	}
+
+	return locations
+}
+
+// matchingBrace returns the position of the closing brace matching the opening
+// one at the specified position. Returns -1 if there is no matching brace.
+func matchingBrace(str string, open int) int {
+	depth := 0
+	var quote byte
+
+	for i := open; i < len(str); i++ {
+		c := str[i]
+
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// An interpolationValue represents the intermediate value of the evaluated
+// interpolation expression which is either a string or a list of strings.
+type interpolationValue struct {
+	// str specifies the string value.
+	str string
+
+	// list specifies the list value.
+	list []string
+
+	// isList specifies if the value is a list.
+	isList bool
+
+	// isVersion specifies if the value is derived from the version, so the
+	// version methods can be called on it.
+	isVersion bool
+}
+
+// An interpolationCall represents a single method call in the interpolation
+// expression.
+type interpolationCall struct {
+	// method specifies the method name.
+	method string
+
+	// args specify the method arguments.
+	args []interpolationArg
+}
+
+// An interpolationArg represents a single method call argument in the
+// interpolation expression.
+type interpolationArg struct {
+	// value specifies the argument value. For regular expressions, it's already
+	// converted to the Go syntax.
+	value string
+
+	// isRegexp specifies if the argument is a regular expression.
+	isRegexp bool
+}
+
+// pattern returns the argument as a regular expression pattern. The string
+// arguments are matched literally.
+func (a interpolationArg) pattern() string {
+	if a.isRegexp {
+		return a.value
+	}
+
+	return regexp.QuoteMeta(a.value)
+}
+
+// call calls the method on the interpolationValue and returns the result. The
+// receiver specifies the expression receiver name used in error messages.
+func (v interpolationValue) call(receiver string, c interpolationCall) (interpolationValue, error) {
+	if v.isList {
+		return v.callList(c)
+	}
+
+	version := NewVersion(v.str)
+	result := interpolationValue{isVersion: v.isVersion}
+
+	switch c.method {
+	case "to_s":
+		result.str = v.str
+	case "upcase":
+		result.str = strings.ToUpper(v.str)
+	case "downcase":
+		result.str = strings.ToLower(v.str)
+	case "strip":
+		result.str = strings.TrimSpace(v.str)
+	case "chomp":
+		if len(c.args) > 1 {
+			return result, c.argumentsError()
+		}
+
+		result.str = rubyChomp(v.str)
+		if len(c.args) == 1 {
+			// Ruby's String#chomp accepts only the string suffixes
+			if c.args[0].isRegexp {
+				return result, c.regexpArgumentError()
+			}
+
+			result.str = version.Chomp(c.args[0].value)
+		}
+	case "sub", "gsub":
+		if len(c.args) != 2 {
+			return result, c.argumentsError()
+		}
+
+		replace := version.Sub
+		if c.method == "gsub" {
+			replace = version.Gsub
+		}
+
+		str, err := replace(c.args[0].pattern(), rubyReplacement(c.args[1].value))
+		if err != nil {
+			return result, err
+		}
+		result.str = str
+	case "split":
+		if len(c.args) > 1 {
+			return result, c.argumentsError()
+		}
+
+		result.isList = true
+
+		if len(c.args) == 1 && c.args[0].isRegexp {
+			re, err := regexp.Compile(c.args[0].value)
+			if err != nil {
+				return result, err
+			}
+
+			result.list = re.Split(v.str, -1)
+			break
+		}
+
+		separator := ""
+		if len(c.args) == 1 {
+			separator = c.args[0].value
+		}
+
+		result.list = version.Split(separator)
+	case "csv":
+		if !v.isVersion {
+			return result, fmt.Errorf("method '%s' is not supported for '%s'", c.method, receiver)
+		}
+
+		result.isList = true
+		result.list = version.CSV()
+	default:
+		if !v.isVersion {
+			return result, fmt.Errorf("method '%s' is not supported for '%s'", c.method, receiver)
+		}
+
+		fn, ok := versionMethods[c.method]
+		if !ok {
+			return result, fmt.Errorf("unknown version method '%s'", c.method)
+		}
+
+		if len(c.args) > 0 {
+			return result, c.argumentsError()
+		}
+
+		// if the method doesn't match the version, the value is left unchanged
+		result.str = v.str
+		if str, err := fn(*version); err == nil {
+			result.str = str
+		}
+	}
+
+	return result, nil
+}
+
+// callList calls the method on the list interpolationValue and returns the
+// result.
+func (v interpolationValue) callList(c interpolationCall) (interpolationValue, error) {
+	result := interpolationValue{isVersion: v.isVersion}
+
+	index := -1
+	switch c.method {
+	case "first":
+		index = 0
+	case "second":
+		index = 1
+	case "third":
+		index = 2
+	case "last":
+		index = len(v.list) - 1
+	case "join":
+		if len(c.args) > 1 {
+			return result, c.argumentsError()
+		}
+
+		separator := ""
+		if len(c.args) == 1 {
+			separator = c.args[0].value
+		}
+
+		result.str = strings.Join(v.list, separator)
+		return result, nil
+	default:
+		return result, fmt.Errorf("method '%s' is not supported for lists", c.method)
+	}
+
+	if len(c.args) > 0 {
+		return result, c.argumentsError()
+	}
+
+	if index < 0 || index >= len(v.list) {
+		return result, fmt.Errorf("method '%s' is out of the list range", c.method)
+	}
+
+	result.str = v.list[index]
+	return result, nil
+}
+
+// rubyReplacement converts the Ruby "sub" and "gsub" replacement string, which
+// references the submatches using the "\1" syntax, to the Go one.
+func rubyReplacement(replacement string) string {
+	var result strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+
+		switch {
+		case c == '$':
+			result.WriteString("$$")
+		case c == '\\' && i+1 < len(replacement) && isDigit(rune(replacement[i+1])):
+			result.WriteString("${" + string(replacement[i+1]) + "}")
+			i++
+		default:
+			result.WriteByte(c)
+		}
+	}
+
+	return result.String()
+}
+
+// rubyChomp removes a single trailing "\r\n", "\n" or "\r" like the Ruby
+// String#chomp without arguments.
+func rubyChomp(str string) string {
+	switch {
+	case strings.HasSuffix(str, "\r\n"):
+		return str[:len(str)-2]
+	case strings.HasSuffix(str, "\n"), strings.HasSuffix(str, "\r"):
+		return str[:len(str)-1]
+	}
+
+	return str
+}
+
+// argumentsError returns the error about the wrong number of the method
+// arguments.
+func (c interpolationCall) argumentsError() error {
+	return fmt.Errorf("wrong number of arguments (%d) for method '%s'", len(c.args), c.method)
+}
+
+// regexpArgumentError returns an error for the method that doesn't support the
+// regular expression arguments.
+func (c interpolationCall) regexpArgumentError() error {
+	return fmt.Errorf("method '%s' doesn't support regular expression arguments", c.method)
+}
+
+// parseInterpolationExpression parses the interpolation expression (without
+// the surrounding "#{" and "}") into the receiver name and the chain of method
+// calls. Supported method arguments are strings, regular expressions and
+// integers.
+func parseInterpolationExpression(expression string) (receiver string, calls []interpolationCall, err error) {
+	s := &expressionScanner{input: expression}
+
+	s.skipSpaces()
+	receiver = s.identifier()
+	if receiver == "" {
+		return "", nil, fmt.Errorf("invalid expression '%s'", expression)
+	}
+
+	for {
+		s.skipSpaces()
+		if s.done() {
+			return receiver, calls, nil
+		}
+
+		if !s.consume('.') {
+			return "", nil, fmt.Errorf("unexpected character '%c' at %d", s.peek(), s.pos)
+		}
+
+		s.skipSpaces()
+		c := interpolationCall{method: s.identifier()}
+		if c.method == "" {
+			return "", nil, fmt.Errorf("method name expected at %d", s.pos)
+		}
+
+		if s.consume('(') {
+			c.args, err = s.arguments()
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		calls = append(calls, c)
+	}
+}
+
+// An expressionScanner represents the scanner of the interpolation
+// expressions.
+type expressionScanner struct {
+	// input specifies the expression being scanned.
+	input string
+
+	// pos specifies the current position in the input.
+	pos int
+}
+
+// done checks whether the whole input has been scanned.
+func (s *expressionScanner) done() bool {
+	return s.pos >= len(s.input)
+}
+
+// peek returns but doesn't consume the next character.
+func (s *expressionScanner) peek() byte {
+	if s.done() {
+		return 0
+	}
+	return s.input[s.pos]
+}
+
+// consume consumes the next character if it matches the specified one.
+func (s *expressionScanner) consume(c byte) bool {
+	if s.peek() == c && !s.done() {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpaces skips all whitespace characters.
+func (s *expressionScanner) skipSpaces() {
+	for !s.done() && (s.input[s.pos] == ' ' || s.input[s.pos] == '\t') {
+		s.pos++
+	}
+}
+
+// identifier scans the Ruby identifier and returns it. Returns an empty string
+// if there is no identifier at the current position.
+func (s *expressionScanner) identifier() string {
+	start := s.pos
+	for !s.done() {
+		c := s.input[s.pos]
+		if !isLetter(rune(c)) && !isDigit(rune(c)) && !(s.pos > start && (c == '?' || c == '!')) {
+			break
+		}
+		s.pos++
+	}
+
+	return s.input[start:s.pos]
+}
+
+// arguments scans the method arguments until the closing parenthesis.
+func (s *expressionScanner) arguments() (args []interpolationArg, err error) {
+	for {
+		s.skipSpaces()
+		if s.consume(')') {
+			return args, nil
+		}
+
+		if len(args) > 0 && !s.consume(',') {
+			return nil, fmt.Errorf("expected ',' or ')' at %d", s.pos)
+		}
+
+		s.skipSpaces()
+
+		var arg interpolationArg
+		switch c := s.peek(); {
+		case c == '\'' || c == '"':
+			arg.value, err = s.quoted(c)
+		case c == '/':
+			arg.value, err = s.regexp()
+			arg.isRegexp = true
+		case isDigit(rune(c)) || c == '-':
+			arg.value, err = s.integer()
+		default:
+			err = fmt.Errorf("unsupported argument at %d", s.pos)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+	}
+}
+
+// quoted scans the Ruby string quoted with the specified quote character and
+// returns its value.
+func (s *expressionScanner) quoted(quote byte) (string, error) {
+	start := s.pos
+	s.pos++
+
+	var value strings.Builder
+	for !s.done() {
+		c := s.input[s.pos]
+		s.pos++
+
+		switch {
+		case c == quote:
+			return value.String(), nil
+		case c == '\\' && !s.done():
+			next := s.input[s.pos]
+			s.pos++
+
+			switch {
+			case next == quote || next == '\\':
+				value.WriteByte(next)
+			case quote == '"' && next == 'n':
+				value.WriteByte('\n')
+			case quote == '"' && next == 't':
+				value.WriteByte('\t')
+			default:
+				value.WriteByte(c)
+				value.WriteByte(next)
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return "", fmt.Errorf("unterminated string at %d", start)
+}
+
+// regexp scans the Ruby regular expression literal ("/pattern/flags") and
+// returns it converted to the Go syntax.
+func (s *expressionScanner) regexp() (string, error) {
+	start := s.pos
+	s.pos++
+
+	var pattern strings.Builder
+	for !s.done() {
+		c := s.input[s.pos]
+		s.pos++
+
+		switch {
+		case c == '/':
+			flags := ""
+			for !s.done() && strings.IndexByte("imx", s.input[s.pos]) >= 0 {
+				switch s.input[s.pos] {
+				case 'i':
+					flags += "i"
+				case 'm':
+					flags += "s"
+				}
+				s.pos++
+			}
+
+			if flags != "" {
+				return "(?" + flags + ")" + pattern.String(), nil
+			}
+			return pattern.String(), nil
+		case c == '\\' && !s.done():
+			next := s.input[s.pos]
+			s.pos++
+
+			if next != '/' {
+				pattern.WriteByte(c)
+			}
+			pattern.WriteByte(next)
+		default:
+			pattern.WriteByte(c)
+		}
+	}
+
+	return "", fmt.Errorf("unterminated regular expression at %d", start)
+}
+
+// integer scans the integer and returns it as a string.
+func (s *expressionScanner) integer() (string, error) {
+	start := s.pos
+	s.consume('-')
+	for !s.done() && isDigit(rune(s.input[s.pos])) {
+		s.pos++
+	}
+
+	if _, err := strconv.Atoi(s.input[start:s.pos]); err != nil {
+		return "", fmt.Errorf("invalid integer at %d", start)
+	}
+
+	return s.input[start:s.pos], nil
+}
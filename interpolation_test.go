@@ -0,0 +1,220 @@
+package cask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestInterpolator() *Interpolator {
+	return NewInterpolator(NewVersion("1.2.3,1000"), map[string]string{
+		VariableAppdir:       "/Applications",
+		VariableCaskroomPath: "/usr/local/Caskroom",
+		VariableToken:        "example",
+		VariableLanguage:     "en",
+		"HOMEPAGE":           "https://example.com/",
+	})
+}
+
+func TestNewInterpolator(t *testing.T) {
+	// preparations
+	v := NewVersion("1.0.0")
+	i := NewInterpolator(v, map[string]string{"token": "example"})
+
+	// test
+	assert.IsType(t, Interpolator{}, *i)
+	assert.Equal(t, v, i.Version)
+	assert.Equal(t, map[string]string{"token": "example"}, i.Variables)
+}
+
+func TestDefaultInterpolationVariables(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"appdir":        "/Applications",
+		"caskroom_path": "/usr/local/Caskroom",
+	}, DefaultInterpolationVariables())
+}
+
+func TestInterpolatorHasInterpolation(t *testing.T) {
+	testCases := map[string]bool{
+		"#{version}":          true,
+		"#{appdir}/Test.app":  true,
+		"#{version.major}.x":  true,
+		"#{version":           false,
+		"#version":            false,
+		"without":             false,
+		"{version}":           false,
+		"#{HOMEPAGE}download": true,
+	}
+
+	for testCase, expected := range testCases {
+		assert.Equal(t, expected, createTestInterpolator().HasInterpolation(testCase), testCase)
+	}
+}
+
+func TestInterpolatorInterpolate(t *testing.T) {
+	testCases := map[string]string{
+		"#{version}":                            "1.2.3,1000",
+		"#{version.before_comma.major_minor}":   "1.2",
+		"#{appdir}/Example.app":                 "/Applications/Example.app",
+		"#{caskroom_path}":                      "/usr/local/Caskroom",
+		"#{staged_path}/Example.app":            "/usr/local/Caskroom/example/1.2.3,1000/Example.app",
+		"#{token}-#{language}":                  "example-en",
+		"#{HOMEPAGE}download":                   "https://example.com/download",
+		"#{ version }":                          "1.2.3,1000",
+		"without interpolation":                 "without interpolation",
+		"#{version} and #{version.after_comma}": "1.2.3,1000 and 1000",
+	}
+
+	for testCase, expected := range testCases {
+		actual, errs := createTestInterpolator().Interpolate(testCase)
+		assert.Equal(t, expected, actual, testCase)
+		assert.Len(t, errs, 0, testCase)
+	}
+}
+
+func TestInterpolatorInterpolateErrors(t *testing.T) {
+	testCases := map[string][]string{
+		"#{arch}/#{version}": {
+			"#{arch}/1.2.3,1000",
+			"unresolved interpolation '#{arch}': variable 'arch' is not set",
+		},
+		"#{version.unknown}": {
+			"#{version.unknown}",
+			"unresolved interpolation '#{version.unknown}': unknown version method 'unknown'",
+		},
+		"#{appdir.major}": {
+			"#{appdir.major}",
+			"unresolved interpolation '#{appdir.major}': method 'major' is not supported for 'appdir'",
+		},
+	}
+
+	for testCase, expected := range testCases {
+		actual, errs := createTestInterpolator().Interpolate(testCase)
+		assert.Equal(t, expected[0], actual, testCase)
+		assert.Len(t, errs, 1, testCase)
+		assert.IsType(t, &InterpolationError{}, errs[0])
+		assert.Equal(t, expected[1], errs[0].Error())
+	}
+
+	// test (without version)
+	i := NewInterpolator(nil, map[string]string{VariableCaskroomPath: "/caskroom", VariableToken: "example"})
+	actual, errs := i.Interpolate("#{version}: #{staged_path}")
+	assert.Equal(t, "#{version}: #{staged_path}", actual)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "unresolved interpolation '#{version}': variable 'version' is not set", errs[0].Error())
+	assert.Equal(t, "unresolved interpolation '#{staged_path}': variable 'version' is not set", errs[1].Error())
+}
+
+func TestFindInterpolations(t *testing.T) {
+	assert.Equal(t, [][2]int{{0, 10}, {11, 24}}, findInterpolations("#{version} #{a.sub('}')}"))
+	assert.Equal(t, [][2]int{{0, 21}}, findInterpolations(`#{a.sub(/\d{2}/, '')}`))
+	assert.Len(t, findInterpolations("#{version"), 0)
+}
+
+func TestInterpolatorInterpolateMethods(t *testing.T) {
+	testCases := map[string]map[string]string{
+		"1.2.3,1000": {
+			"#{version.csv.first}":                   "1.2.3",
+			"#{version.csv.second}":                  "1000",
+			"#{version.csv.last}":                    "1000",
+			"#{version.csv.first.major_minor}":       "1.2",
+			"#{version.csv.join('-')}":               "1.2.3-1000",
+			"#{version.split('.').first}":            "1",
+			"#{version.split(\".\").third}":          "3,1000",
+			"#{version.split(/\\./).first}":          "1",
+			"#{version.split(/[.,]/).last}":          "1000",
+			"#{version.sub(/\\./, '')}":              "12.3,1000",
+			"#{version.gsub(/\\./, '')}":             "123,1000",
+			"#{version.gsub('.', '_')}":              "1_2_3,1000",
+			"#{version.sub(/(\\d+),(\\d+)/, '\\2')}": "1.2.1000",
+			"#{version.sub(/^/, '$')}":               "$1.2.3,1000",
+			"#{version.before_comma.chomp('.3')}":    "1.2",
+			"#{version.no_dividers}":                 "123,1000",
+			"#{version.dots_to_slashes}":             "1/2/3,1000",
+			"#{version.upcase}":                      "1.2.3,1000",
+			"#{ version . csv . first }":             "1.2.3",
+		},
+		"2019.1-beta2": {
+			"#{version.before_hyphen}":      "2019.1",
+			"#{version.after_hyphen}":       "beta2",
+			"#{version.hyphens_to_dots}":    "2019.1.beta2",
+			"#{version.no_hyphens}":         "2019.1beta2",
+			"#{version.chomp}":              "2019.1-beta2",
+			"#{version.sub(/BETA/i, 'rc')}": "2019.1-rc2",
+		},
+		"1.0.0": {
+			"#{version.chomp('.0')}": "1.0",
+			"#{version.after_comma}": "1.0.0",
+		},
+		"a\n\n": {
+			"#{version.chomp}":       "a\n",
+			"#{version.chomp.chomp}": "a",
+		},
+		"a\r\n": {
+			"#{version.chomp}": "a",
+		},
+		"a\n\r": {
+			"#{version.chomp}": "a\n",
+		},
+	}
+
+	for version, cases := range testCases {
+		i := NewInterpolator(NewVersion(version), nil)
+		for testCase, expected := range cases {
+			actual, errs := i.Interpolate(testCase)
+			assert.Equal(t, expected, actual, testCase)
+			assert.Len(t, errs, 0, testCase)
+		}
+	}
+}
+
+func TestInterpolatorInterpolateMethodsErrors(t *testing.T) {
+	testCases := map[string]string{
+		"#{version.csv}":                 "expression 'version.csv' evaluates to a list",
+		"#{version.csv.third}":           "method 'third' is out of the list range",
+		"#{version.csv.major}":           "method 'major' is not supported for lists",
+		"#{version.csv.first(1)}":        "wrong number of arguments (1) for method 'first'",
+		"#{version.chomp('a', 'b')}":     "wrong number of arguments (2) for method 'chomp'",
+		"#{version.sub(/a/)}":            "wrong number of arguments (1) for method 'sub'",
+		"#{version.major(1)}":            "wrong number of arguments (1) for method 'major'",
+		"#{version.sub(/(/, '')}":        "error parsing regexp: missing closing ): `(`",
+		"#{version.sub(/a, '')}":         "unterminated regular expression at 12",
+		"#{version.chomp(a)}":            "unsupported argument at 14",
+		"#{version.chomp('a' 'b')}":      "expected ',' or ')' at 18",
+		"#{version..major}":              "method name expected at 8",
+		"#{version major}":               "unexpected character 'm' at 8",
+		"#{(version)}":                   "invalid expression '(version)'",
+		"#{token.csv}":                   "method 'csv' is not supported for 'token'",
+		"#{version.split('.').first(1)}": "wrong number of arguments (1) for method 'first'",
+		"#{version.chomp(/\\.3/)}":       "method 'chomp' doesn't support regular expression arguments",
+		"#{version.split(/(/)}":          "error parsing regexp: missing closing ): `(`",
+	}
+
+	i := NewInterpolator(NewVersion("1.2.3"), map[string]string{VariableToken: "example"})
+	for testCase, expected := range testCases {
+		actual, errs := i.Interpolate(testCase)
+		assert.Equal(t, testCase, actual)
+		if assert.Len(t, errs, 1, testCase) {
+			assert.Equal(t, "unresolved interpolation '"+testCase+"': "+expected, errs[0].Error())
+		}
+	}
+}
+
+func TestParseInterpolationExpression(t *testing.T) {
+	// test (successful)
+	receiver, calls, err := parseInterpolationExpression(`version.sub(/\/(\d)/i, "\t").split(',', -1).first`)
+	assert.Nil(t, err)
+	assert.Equal(t, "version", receiver)
+	assert.Equal(t, []interpolationCall{
+		{method: "sub", args: []interpolationArg{{`(?i)/(\d)`, true}, {"\t", false}}},
+		{method: "split", args: []interpolationArg{{",", false}, {"-1", false}}},
+		{method: "first"},
+	}, calls)
+
+	// test (error)
+	_, _, err = parseInterpolationExpression("version.chomp('a)")
+	assert.EqualError(t, err, "unterminated string at 14")
+
+	_, _, err = parseInterpolationExpression("version.chomp(-)")
+	assert.EqualError(t, err, "invalid integer at 14")
+}
@@ -0,0 +1,634 @@
+package cask
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A caskJSON represents the JSON encoding of the Cask compatible with the
+// "brew info --json=v2" output. The Variant supporting the latest macOS
+// release is encoded at the top level, while the rest of the variants are
+// encoded as the differences from it in the variations.
+type caskJSON struct {
+	Token string `json:"token"`
+
+	VariantSchema
+
+	DependsOn         map[string]interface{} `json:"depends_on"`
+	Deprecated        bool                   `json:"deprecated"`
+	DeprecationDate   *string                `json:"deprecation_date"`
+	DeprecationReason *string                `json:"deprecation_reason"`
+	Disabled          bool                   `json:"disabled"`
+	DisableDate       *string                `json:"disable_date"`
+	DisableReason     *string                `json:"disable_reason"`
+	Variations        variationsJSON         `json:"variations"`
+}
+
+// A variationsJSON represents the JSON encoding of the differences between the
+// variants and the top level Variant keyed by the macOS release symbols. The
+// keys for the ARM architecture have the "arm64_" prefix.
+type variationsJSON map[string]map[string]json.RawMessage
+
+// variationARMPrefix specifies the variationsJSON key prefix for the ARM
+// architecture. For example, "arm64_sierra".
+const variationARMPrefix = "arm64_"
+
+// MarshalJSON returns the JSON encoding of the Cask compatible with the
+// "brew info --json=v2" output: "token", "name", "desc", "homepage", "url",
+// "appcast", "version", "sha256", "artifacts", "depends_on", the deprecation
+// fields and "variations". All interpolations are resolved. For the casks with
+// the architecture-specific variants, the top level fields are the ones used
+// on ARM and the variations are written for each architecture. The
+// "deprecated" and "disabled" are evaluated as of the
+// ParseOptions.ReferenceTime, so the encoding doesn't depend on the clock.
+func (c Cask) MarshalJSON() ([]byte, error) {
+	options := c.parseOptions()
+	data := caskJSON{
+		Token:      c.Token,
+		DependsOn:  make(map[string]interface{}),
+		Variations: make(variationsJSON),
+	}
+
+	if len(c.Variants) == 0 {
+		data.VariantSchema = newBrewVariantSchema(NewVariant(), ArchAll)
+		return json.Marshal(data)
+	}
+
+	// the top level fields are the ones used on ARM if the architectures differ
+	archs := c.variationArchs()
+	baseArch := archs[len(archs)-1]
+
+	base := c.Variants[len(c.Variants)-1]
+	if i := c.variantIndex(options.LatestMacOS(), baseArch); i >= 0 {
+		base = c.Variants[i]
+	}
+
+	data.VariantSchema = newBrewVariantSchema(base, baseArch)
+
+	var err error
+	if data.Variations, err = c.variations(data.VariantSchema, archs); err != nil {
+		return nil, err
+	}
+
+	// the releases without any variant are unsupported
+	oldest := options.OldestMacOS()
+	for oldest > options.LatestMacOS() && c.variantIndex(oldest, ArchAll) < 0 {
+		oldest--
+	}
+
+	if oldest != options.OldestMacOS() {
+		data.DependsOn["macos"] = map[string][]string{">=": {oldest.Version()}}
+	}
+
+	if at := options.ReferenceTime; at.IsZero() {
+		data.Deprecated = c.Lifecycle.Deprecated != nil || c.Lifecycle.Disabled != nil
+		data.Disabled = c.Lifecycle.Disabled != nil
+	} else {
+		data.Deprecated = c.Lifecycle.IsDeprecated(at)
+		data.Disabled = c.Lifecycle.IsDisabled(at)
+	}
+
+	if s := newLifecycleSchema(c.Lifecycle.Deprecated); s != nil {
+		data.DeprecationDate, data.DeprecationReason = s.Date, s.Reason
+	}
+
+	if s := newLifecycleSchema(c.Lifecycle.Disabled); s != nil {
+		data.DisableDate, data.DisableReason = s.Date, s.Reason
+	}
+
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON decodes the Cask from the JSON encoding compatible with the
+// "brew info --json=v2" output. Each group of the adjacent macOS releases with
+// the same variations becomes a separate Variant, while the top level fields
+// become the Variant supporting the latest macOS release. The variations of the
+// unknown macOS releases, the unsupported artifacts and the "desc" are
+// ignored.
+func (c *Cask) UnmarshalJSON(data []byte) error {
+	var decoded caskJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	options := c.parseOptions()
+
+	c.Token = decoded.Token
+	c.Variants = nil
+	c.Lifecycle = Lifecycle{}
+
+	// the keys without the "arm64_" prefix are for Intel only if the
+	// architectures differ: either there are ARM keys or Intel differs from the
+	// top level fields on the latest release
+	plainArch := ArchAll
+	if _, ok := decoded.Variations[options.LatestMacOS().Symbol()]; ok {
+		plainArch = ArchIntel
+	}
+
+	type release struct {
+		key  string
+		mac  MacOS
+		arch Arch
+	}
+
+	releases := make([]release, 0, len(decoded.Variations))
+	for key := range decoded.Variations {
+		symbol, arch := strings.TrimPrefix(key, variationARMPrefix), ArchARM
+		if symbol == key {
+			arch = ArchAll
+		} else {
+			plainArch = ArchIntel
+		}
+
+		if m, ok := macOSFromSymbol(symbol); ok {
+			releases = append(releases, release{key, m, arch})
+		}
+	}
+
+	for i := range releases {
+		if releases[i].arch == ArchAll {
+			releases[i].arch = plainArch
+		}
+	}
+
+	// the adjacent releases with the same variations are grouped
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].arch != releases[j].arch {
+			return releases[i].arch < releases[j].arch
+		}
+		return releases[i].mac > releases[j].mac
+	})
+
+	var previous string
+	for i, r := range releases {
+		variation, err := json.Marshal(decoded.Variations[r.key])
+		if err != nil {
+			return err
+		}
+
+		if i > 0 && string(variation) == previous && releases[i-1].arch == r.arch && releases[i-1].mac == r.mac+1 {
+			c.Variants[len(c.Variants)-1].MaximumSupportedMacOS = r.mac
+			continue
+		}
+		previous = string(variation)
+
+		merged := make(map[string]json.RawMessage, len(fields))
+		for name, value := range fields {
+			merged[name] = value
+		}
+
+		for name, value := range decoded.Variations[r.key] {
+			merged[name] = value
+		}
+
+		v, err := unmarshalBrewVariant(merged)
+		if err != nil {
+			return err
+		}
+
+		v.MinimumSupportedMacOS = r.mac
+		v.MaximumSupportedMacOS = r.mac
+		v.Arch = r.arch
+		c.AddVariant(v)
+	}
+
+	// similar to the parsed "else" branch, the top level fields are used for
+	// the latest macOS release
+	base, err := brewVariant(decoded.VariantSchema)
+	if err != nil {
+		return err
+	}
+
+	base.MinimumSupportedMacOS = options.LatestMacOS()
+	base.MaximumSupportedMacOS = options.LatestMacOS()
+	c.AddVariant(base)
+
+	if decoded.Deprecated || decoded.DeprecationDate != nil || decoded.DeprecationReason != nil {
+		s := LifecycleSchema{decoded.DeprecationDate, decoded.DeprecationReason}
+		if c.Lifecycle.Deprecated, err = s.event(); err != nil {
+			return err
+		}
+	}
+
+	if decoded.Disabled || decoded.DisableDate != nil || decoded.DisableReason != nil {
+		s := LifecycleSchema{decoded.DisableDate, decoded.DisableReason}
+		if c.Lifecycle.Disabled, err = s.event(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// variations returns the differences between the Variant used on each
+// targeted macOS release and the provided top level VariantSchema for each of
+// the provided architectures. Like in Ruby, the release supported by multiple
+// variants uses the earliest one.
+func (c *Cask) variations(base VariantSchema, archs []Arch) (variationsJSON, error) {
+	baseFields, err := jsonFields(base)
+	if err != nil {
+		return nil, err
+	}
+
+	type variant struct {
+		index int
+		arch  Arch
+	}
+
+	options := c.parseOptions()
+	diffs := make(map[variant]map[string]json.RawMessage)
+	variations := make(variationsJSON)
+
+	for m := options.LatestMacOS(); m <= options.OldestMacOS(); m++ {
+		for _, arch := range archs {
+			i := c.variantIndex(m, arch)
+			if i < 0 {
+				continue
+			}
+
+			diff, ok := diffs[variant{i, arch}]
+			if !ok {
+				fields, err := jsonFields(newBrewVariantSchema(c.Variants[i], arch))
+				if err != nil {
+					return nil, err
+				}
+
+				diff = make(map[string]json.RawMessage)
+				for name, value := range fields {
+					if string(baseFields[name]) != string(value) {
+						diff[name] = value
+					}
+				}
+				diffs[variant{i, arch}] = diff
+			}
+
+			if len(diff) > 0 {
+				variations[variationKey(m, arch)] = diff
+			}
+		}
+	}
+
+	return variations, nil
+}
+
+// variationArchs returns the architectures the variations are written for:
+// Intel and ARM if at least one Variant is architecture-specific, otherwise
+// only the ArchAll.
+func (c *Cask) variationArchs() []Arch {
+	for _, v := range c.Variants {
+		if v.Arch != ArchAll {
+			return []Arch{ArchIntel, ArchARM}
+		}
+	}
+
+	return []Arch{ArchAll}
+}
+
+// variationKey returns the variationsJSON key of the provided macOS release
+// and architecture. For example, "sierra" or "arm64_sierra".
+func variationKey(mac MacOS, arch Arch) string {
+	if arch == ArchARM {
+		return variationARMPrefix + mac.Symbol()
+	}
+
+	return mac.Symbol()
+}
+
+// parseOptions returns the ParseOptions of the Cask parser. The default ones
+// are returned for the casks created without the NewCask.
+func (c *Cask) parseOptions() *ParseOptions {
+	if c.parser != nil {
+		return &c.parser.options
+	}
+
+	return NewParseOptions()
+}
+
+// newBrewVariantSchema creates a new VariantSchema from the provided Variant
+// used on the provided architecture with only the fields used in the "brew
+// info --json=v2" output.
+func newBrewVariantSchema(v *Variant, arch Arch) VariantSchema {
+	s := NewVariantSchema(v.resolved(arch))
+	s.MinimumMacOS = ""
+	s.MaximumMacOS = ""
+	s.Arch = ""
+	s.Livecheck = nil
+
+	return s
+}
+
+// brewVariant returns the Variant represented by the VariantSchema decoded
+// from the "brew info --json=v2" output. The fields missing in that output are
+// ignored.
+func brewVariant(s VariantSchema) (*Variant, error) {
+	s.MinimumMacOS = ""
+	s.MaximumMacOS = ""
+	s.Arch = ""
+	s.Livecheck = nil
+
+	return s.Variant()
+}
+
+// unmarshalBrewVariant decodes the Variant from the provided JSON fields of
+// the "brew info --json=v2" output.
+func unmarshalBrewVariant(fields map[string]json.RawMessage) (*Variant, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var s VariantSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return brewVariant(s)
+}
+
+// jsonFields returns the JSON encoding of each field of the provided value.
+func jsonFields(v interface{}) (fields map[string]json.RawMessage, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// MarshalJSON returns the JSON encoding of the Variant, which is its
+// VariantSchema. All interpolations are resolved.
+func (v Variant) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewVariantSchema(v.resolved(v.Arch)))
+}
+
+// UnmarshalJSON decodes the Variant from the JSON encoding of its
+// VariantSchema. The supported macOS releases and the architecture missing in
+// the encoding aren't changed.
+func (v *Variant) UnmarshalJSON(data []byte) error {
+	var s VariantSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	variant, err := s.Variant()
+	if err != nil {
+		return err
+	}
+
+	if s.MinimumMacOS == "" {
+		variant.MinimumSupportedMacOS = v.MinimumSupportedMacOS
+	}
+
+	if s.MaximumMacOS == "" {
+		variant.MaximumSupportedMacOS = v.MaximumSupportedMacOS
+	}
+
+	if s.Arch == "" {
+		variant.Arch = v.Arch
+	}
+
+	*v = *variant
+
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of the ArtifactSchema used in the
+// Homebrew-Cask JSON API: an object with the artifact type as the only key and
+// the arguments array as the value.
+func (s ArtifactSchema) MarshalJSON() ([]byte, error) {
+	args := []interface{}{s.Value}
+
+	options := make(map[string]interface{})
+	if s.Target != "" {
+		options["target"] = s.Target
+	}
+
+	if s.AllowUntrusted {
+		options["allow_untrusted"] = true
+	}
+
+	if len(options) > 0 {
+		args = append(args, options)
+	}
+
+	return json.Marshal(map[string][]interface{}{s.Type: args})
+}
+
+// UnmarshalJSON decodes the ArtifactSchema from the JSON encoding used in the
+// Homebrew-Cask JSON API.
+func (s *ArtifactSchema) UnmarshalJSON(data []byte) error {
+	var decoded map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if len(decoded) != 1 {
+		return fmt.Errorf("expected a single artifact type, found %d", len(decoded))
+	}
+
+	for name, args := range decoded {
+		if len(args) == 0 {
+			return fmt.Errorf("%s artifact has no value", name)
+		}
+
+		var value string
+		if err := json.Unmarshal(args[0], &value); err != nil {
+			return err
+		}
+
+		var options struct {
+			Target         string `json:"target"`
+			AllowUntrusted bool   `json:"allow_untrusted"`
+		}
+
+		if len(args) > 1 {
+			if err := json.Unmarshal(args[1], &options); err != nil {
+				return err
+			}
+		}
+
+		*s = ArtifactSchema{name, value, options.Target, options.AllowUntrusted}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes the artifacts of the supported types skipping the
+// rest.
+func (a *ArtifactSchemas) UnmarshalJSON(data []byte) error {
+	var artifacts []json.RawMessage
+	if err := json.Unmarshal(data, &artifacts); err != nil {
+		return err
+	}
+
+	*a = make(ArtifactSchemas, 0, len(artifacts))
+	for _, data := range artifacts {
+		var s ArtifactSchema
+		if err := s.UnmarshalJSON(data); err != nil {
+			continue
+		}
+
+		if _, ok := artifactTypeFromName(s.Type); ok {
+			*a = append(*a, s)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of the Version, which is the
+// Version.Value string.
+func (v Version) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Value)
+}
+
+// UnmarshalJSON decodes the Version.Value from the JSON string.
+func (v *Version) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.Value)
+}
+
+// MarshalJSON returns the JSON encoding of the SHA256, which is the
+// SHA256.Value string.
+func (s SHA256) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Value)
+}
+
+// UnmarshalJSON decodes the SHA256.Value from the JSON string.
+func (s *SHA256) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s.Value)
+}
+
+// MarshalJSON returns the JSON encoding of the URL, which is the URL.Value
+// string.
+func (u URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Value)
+}
+
+// UnmarshalJSON decodes the URL.Value from the JSON string.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &u.Value)
+}
+
+// MarshalJSON returns the JSON encoding of the Appcast, which is the
+// Appcast.URL string.
+func (a Appcast) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.URL)
+}
+
+// UnmarshalJSON decodes the Appcast.URL from the JSON string.
+func (a *Appcast) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &a.URL)
+}
+
+// MarshalJSON returns the JSON encoding of the Name, which is the Name.Value
+// string.
+func (n Name) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalJSON decodes the Name.Value from the JSON string.
+func (n *Name) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &n.Value)
+}
+
+// MarshalJSON returns the JSON encoding of the Homepage, which is the
+// Homepage.Value string.
+func (h Homepage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Value)
+}
+
+// UnmarshalJSON decodes the Homepage.Value from the JSON string.
+func (h *Homepage) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &h.Value)
+}
+
+// MarshalJSON returns the JSON encoding of the Artifact used in the
+// Homebrew-Cask JSON API: an object with the artifact type as the only key and
+// the arguments array as the value. For example, {"app": ["Example.app",
+// {"target": "Example.app"}]}.
+func (a Artifact) MarshalJSON() ([]byte, error) {
+	return newArtifactSchema(a).MarshalJSON()
+}
+
+// UnmarshalJSON decodes the Artifact from the JSON encoding used in the
+// Homebrew-Cask JSON API. Returns an error if the artifact type isn't
+// supported.
+func (a *Artifact) UnmarshalJSON(data []byte) error {
+	var decoded map[string]json.RawMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	for name := range decoded {
+		if _, ok := artifactTypeFromName(name); !ok {
+			return fmt.Errorf("unsupported artifact type '%s'", name)
+		}
+	}
+
+	var s ArtifactSchema
+	if err := s.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	artifact, err := s.artifact()
+	if err != nil {
+		return err
+	}
+
+	*a = *artifact
+
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of the Livecheck, which is its
+// LivecheckSchema. The URL references are encoded as symbols (for example,
+// ":homepage") and the missing values as nulls.
+func (l Livecheck) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newLivecheckSchema(l))
+}
+
+// UnmarshalJSON decodes the Livecheck from the JSON encoding of its
+// LivecheckSchema. The unknown strategy is kept as Livecheck.UnknownStrategy.
+func (l *Livecheck) UnmarshalJSON(data []byte) error {
+	var s LivecheckSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*l = *s.livecheck()
+
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of the LifecycleEvent, which is its
+// LifecycleSchema with the "date" and the "reason" fields. The missing values
+// are encoded as nulls.
+func (e LifecycleEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newLifecycleSchema(&e))
+}
+
+// UnmarshalJSON decodes the LifecycleEvent from the JSON encoding of its
+// LifecycleSchema. Returns an error if the date can't be parsed.
+func (e *LifecycleEvent) UnmarshalJSON(data []byte) error {
+	var s LifecycleSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	event, err := s.event()
+	if err != nil {
+		return err
+	}
+
+	*e = *event
+
+	return nil
+}
@@ -0,0 +1,453 @@
+package cask
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaskMarshalJSON(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("example-one.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	actual, err := json.Marshal(c)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"token": "example-one",
+		"name": ["Example", "Example One"],
+		"desc": null,
+		"homepage": "https://example.com/",
+		"url": "https://example.com/app_2.0.0.dmg",
+		"appcast": "https://example.com/sparkle/2/appcast.xml",
+		"version": "2.0.0",
+		"sha256": "f22abd6773ab232869321ad4b1e47ac0c908febf4f3a2bd10c8066140f741261",
+		"artifacts": [
+			{"app": ["Example 2.0.app", {"target": "Example.app"}]},
+			{"app": ["Example 2.0 Uninstaller.app"]},
+			{"binary": ["/Applications/Example 2.0.app/Contents/MacOS/example-one", {"target": "example"}]}
+		],
+		"depends_on": {},
+		"deprecated": false,
+		"deprecation_date": null,
+		"deprecation_reason": null,
+		"disabled": false,
+		"disable_date": null,
+		"disable_reason": null,
+		"variations": {}
+	}`, string(actual))
+
+	// test (latest)
+	c = NewCask(string(getTestdata("latest.rb")))
+	assert.Nil(t, c.Parse())
+	c.Variants[0].SHA256 = NewSHA256("no_check")
+
+	actual, err = json.Marshal(c)
+	assert.Nil(t, err)
+	assert.Contains(t, string(actual), `"version":"latest","sha256":"no_check"`)
+
+	// test (missing checksum)
+	c.Variants[0].SHA256 = nil
+
+	actual, err = json.Marshal(c)
+	assert.Nil(t, err)
+	assert.Contains(t, string(actual), `"version":"latest","sha256":null`)
+}
+
+func TestCaskMarshalJSONVariations(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("if-six-versions-six-appcasts.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	data, err := json.Marshal(c)
+	assert.Nil(t, err)
+
+	var actual struct {
+		Version    string                            `json:"version"`
+		DependsOn  map[string]map[string][]string    `json:"depends_on"`
+		Variations map[string]map[string]interface{} `json:"variations"`
+	}
+
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, "2.0.0", actual.Version)
+	assert.Empty(t, actual.DependsOn)
+	assert.Len(t, actual.Variations, 5)
+	assert.Equal(t, map[string]interface{}{
+		"version": "0.1.0",
+		"sha256":  "6ad9613a455798d6d92e5f5f390ab4baa70596bc869ed6b17f5cdd2b28635f06",
+		"url":     "https://example.com/snowleopard/app_0.1.0.dmg",
+		"appcast": "https://example.com/sparkle/0.1.0/snowleopard.xml",
+	}, actual.Variations["snow_leopard"])
+	assert.NotContains(t, actual.Variations, "el_capitan")
+
+	// test (unsupported releases)
+	c.Variants = c.Variants[:len(c.Variants)-1]
+
+	data, err = json.Marshal(c)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, []string{"10.6"}, actual.DependsOn["macos"][">="])
+}
+
+func TestCaskMarshalJSONVariationsOverlapping(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("if-three-versions-one-appcast.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	data, err := json.Marshal(c)
+	assert.Nil(t, err)
+
+	var actual struct {
+		Version    string                            `json:"version"`
+		Variations map[string]map[string]interface{} `json:"variations"`
+	}
+
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, "2.0.0", actual.Version)
+	assert.Len(t, actual.Variations, 2)
+	assert.Equal(t, map[string]interface{}{
+		"version": "0.9.0",
+		"sha256":  "30c99e8b103eacbe6f6d6e1b54b06ca6d5f3164b4f50094334a517ae95ca8fba",
+		"url":     "https://example.com/app_0.9.0.dmg",
+		"appcast": nil,
+	}, actual.Variations["tiger"])
+	assert.Equal(t, map[string]interface{}{
+		"version": "1.0.0",
+		"sha256":  "92521fc3cbd964bdc9f584a991b89fddaa5754ed1cc96d6d42445338669c1305",
+		"url":     "https://example.com/app_1.0.0.dmg",
+		"appcast": nil,
+	}, actual.Variations["leopard"])
+}
+
+func TestCaskMarshalJSONArch(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("on-arch.rb")))
+	assert.Nil(t, c.Parse())
+
+	type caskJSON struct {
+		Version    string                            `json:"version"`
+		URL        string                            `json:"url"`
+		DependsOn  map[string]interface{}            `json:"depends_on"`
+		Variations map[string]map[string]interface{} `json:"variations"`
+	}
+
+	// test
+	data, err := json.Marshal(c)
+	assert.Nil(t, err)
+
+	var actual caskJSON
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, "2.0.0", actual.Version)
+	assert.Equal(t, "https://example.com/app_2.0.0_arm64.dmg", actual.URL)
+	assert.Empty(t, actual.DependsOn)
+	assert.Len(t, actual.Variations, 10)
+	assert.Equal(t, map[string]interface{}{
+		"version": "1.0.0",
+		"sha256":  "cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435",
+		"url":     "https://example.com/app_1.0.0_x86_64.dmg",
+	}, actual.Variations["high_sierra"])
+
+	// test (ARM variations)
+	v := c.Variants[1].clone()
+	v.Version = NewVersion("1.5.0")
+	v.MinimumSupportedMacOS = MacOSTiger
+	v.MaximumSupportedMacOS = MacOSSierra
+	c.Variants = append([]*Variant{v}, c.Variants...)
+
+	data, err = json.Marshal(c)
+	assert.Nil(t, err)
+
+	actual = caskJSON{}
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Len(t, actual.Variations, 19)
+	assert.NotContains(t, actual.Variations, "arm64_high_sierra")
+	assert.Equal(t, map[string]interface{}{
+		"version": "1.5.0",
+		"url":     "https://example.com/app_1.5.0_arm64.dmg",
+	}, actual.Variations["arm64_sierra"])
+
+	var decoded Cask
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	if assert.Len(t, decoded.Variants, 3) {
+		assert.Equal(t, ArchIntel, decoded.Variants[0].Arch)
+		assert.Equal(t, "1.0.0", decoded.Variants[0].GetVersion().Value)
+		assert.Equal(t, ArchARM, decoded.Variants[1].Arch)
+		assert.Equal(t, MacOSTiger, decoded.Variants[1].MinimumSupportedMacOS)
+		assert.Equal(t, MacOSSierra, decoded.Variants[1].MaximumSupportedMacOS)
+		assert.Equal(t, "1.5.0", decoded.Variants[1].GetVersion().Value)
+		assert.Equal(t, ArchAll, decoded.Variants[2].Arch)
+		assert.Equal(t, "2.0.0", decoded.Variants[2].GetVersion().Value)
+	}
+}
+
+func TestCaskMarshalJSONLifecycle(t *testing.T) {
+	// preparations
+	at := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCask(string(getTestdata("deprecated.rb")), WithReferenceTime(at))
+	assert.Nil(t, c.Parse())
+
+	// test
+	data, err := json.Marshal(c)
+	assert.Nil(t, err)
+
+	var actual map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, true, actual["deprecated"])
+	assert.Equal(t, "2023-01-01", actual["deprecation_date"])
+	assert.Equal(t, "discontinued", actual["deprecation_reason"])
+	assert.Equal(t, false, actual["disabled"])
+	assert.Equal(t, "2024-01-01", actual["disable_date"])
+	assert.Equal(t, "is no longer maintained", actual["disable_reason"])
+
+	// test (round trip)
+	var decoded Cask
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, c.Lifecycle, decoded.Lifecycle)
+
+	// test (without the reference time)
+	c = NewCask(string(getTestdata("deprecated.rb")))
+	assert.Nil(t, c.Parse())
+
+	data, err = json.Marshal(c)
+	assert.Nil(t, err)
+
+	again, err := json.Marshal(c)
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), string(again))
+
+	actual = nil
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, true, actual["deprecated"])
+	assert.Equal(t, true, actual["disabled"])
+}
+
+func TestCaskUnmarshalJSON(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("if-six-versions-six-appcasts.rb")))
+	assert.Nil(t, c.Parse())
+
+	data, err := json.Marshal(c)
+	assert.Nil(t, err)
+
+	// test
+	var actual Cask
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, "if-six-versions-six-appcasts", actual.Token)
+	if !assert.Len(t, actual.Variants, 6) {
+		return
+	}
+
+	for i, v := range c.Variants {
+		assert.Equal(t, v.MinimumSupportedMacOS, actual.Variants[i].MinimumSupportedMacOS)
+		assert.Equal(t, v.MaximumSupportedMacOS, actual.Variants[i].MaximumSupportedMacOS)
+		assert.Equal(t, v.GetVersion(), actual.Variants[i].GetVersion())
+		assert.Equal(t, v.GetURL().Value, actual.Variants[i].GetURL().Value)
+		assert.Equal(t, v.GetSHA256(), actual.Variants[i].GetSHA256())
+	}
+
+	// test (grouped variations, unknown releases and artifacts)
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"token": "example",
+		"version": "2.0.0",
+		"sha256": "no_check",
+		"artifacts": [{"app": ["Example.app"]}, {"zap": [{"trash": "~/Library/Example"}]}],
+		"variations": {
+			"sierra": {"version": "1.0.0"},
+			"el_capitan": {"version": "1.0.0"},
+			"yosemite": {"version": "0.1.0"},
+			"unknown": {"version": "0.0.1"}
+		}
+	}`), &actual))
+
+	assert.Equal(t, "example", actual.Token)
+	if !assert.Len(t, actual.Variants, 3) {
+		return
+	}
+	assert.Equal(t, MacOSYosemite, actual.Variants[0].MinimumSupportedMacOS)
+	assert.Equal(t, MacOSYosemite, actual.Variants[0].MaximumSupportedMacOS)
+	assert.Equal(t, "0.1.0", actual.Variants[0].GetVersion().Value)
+	assert.Equal(t, MacOSElCapitan, actual.Variants[1].MinimumSupportedMacOS)
+	assert.Equal(t, MacOSSierra, actual.Variants[1].MaximumSupportedMacOS)
+	assert.Equal(t, "1.0.0", actual.Variants[1].GetVersion().Value)
+	assert.Equal(t, MacOSHighSierra, actual.Variants[2].MinimumSupportedMacOS)
+	assert.Equal(t, MacOSHighSierra, actual.Variants[2].MaximumSupportedMacOS)
+	assert.Equal(t, "2.0.0", actual.Variants[2].GetVersion().Value)
+	assert.Equal(t, NewSHA256("no_check"), actual.Variants[2].SHA256)
+	assert.Equal(t, []*Artifact{NewArtifact(ArtifactApp, "Example.app")}, actual.Variants[2].Artifacts)
+
+	// test (error)
+	assert.Error(t, json.Unmarshal([]byte(`{"deprecation_date": "tomorrow"}`), &actual))
+	assert.Error(t, json.Unmarshal([]byte(`{"token": 1}`), &actual))
+}
+
+func TestCaskJSONRoundTrip(t *testing.T) {
+	// preparations
+	files, err := ioutil.ReadDir(filepath.Join(getWorkingDir(), testdataPath))
+	assert.Nil(t, err)
+
+	// test
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".rb") {
+			continue
+		}
+
+		c := NewCask(string(getTestdata(file.Name())))
+		if c.Parse() != nil {
+			continue
+		}
+
+		expected, err := json.Marshal(c)
+		assert.Nil(t, err, file.Name())
+
+		var decoded Cask
+		assert.Nil(t, json.Unmarshal(expected, &decoded), file.Name())
+
+		actual, err := json.Marshal(decoded)
+		assert.Nil(t, err, file.Name())
+		assert.JSONEq(t, string(expected), string(actual), file.Name())
+	}
+}
+
+func TestVariantJSON(t *testing.T) {
+	// preparations
+	v := NewVariant()
+	v.Version = NewVersion("1.0.0")
+	v.URL = NewURL("https://example.com/app_#{version}.dmg")
+	v.AddName(NewName("Example"))
+	v.AddArtifact(NewArtifact(ArtifactApp, "Example #{version}.app"))
+	v.MinimumSupportedMacOS = MacOSSierra
+	v.MaximumSupportedMacOS = MacOSSierra
+
+	// test
+	data, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"minimum_macos": "sierra",
+		"maximum_macos": "sierra",
+		"name": ["Example"],
+		"desc": null,
+		"homepage": null,
+		"url": "https://example.com/app_1.0.0.dmg",
+		"appcast": null,
+		"version": "1.0.0",
+		"sha256": null,
+		"artifacts": [{"app": ["Example 1.0.0.app"]}]
+	}`, string(data))
+
+	actual := NewVariant()
+	assert.Nil(t, json.Unmarshal(data, actual))
+	assert.Equal(t, "https://example.com/app_1.0.0.dmg", actual.URL.Value)
+	assert.Equal(t, "1.0.0", actual.Version.Value)
+	assert.Nil(t, actual.SHA256)
+	assert.Equal(t, MacOSSierra, actual.MinimumSupportedMacOS)
+	assert.Equal(t, MacOSSierra, actual.MaximumSupportedMacOS)
+
+	// the missing macOS releases aren't changed
+	actual.MinimumSupportedMacOS = MacOSYosemite
+	assert.Nil(t, json.Unmarshal([]byte(`{"version": "2.0.0"}`), actual))
+	assert.Equal(t, "2.0.0", actual.Version.Value)
+	assert.Equal(t, MacOSYosemite, actual.MinimumSupportedMacOS)
+	assert.Equal(t, MacOSSierra, actual.MaximumSupportedMacOS)
+
+	// the "#{arch}" is resolved using the Variant.Arch
+	v.URL = NewURL("https://example.com/app_#{version}_#{arch}.dmg")
+	v.Arch = ArchARM
+	data, err = json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"url":"https://example.com/app_1.0.0_arm64.dmg"`)
+
+	// the unchecked checksum isn't the missing one
+	assert.Nil(t, json.Unmarshal([]byte(`{"sha256": "no_check"}`), actual))
+	assert.Equal(t, NewSHA256("no_check"), actual.SHA256)
+
+	// test (error)
+	assert.Error(t, json.Unmarshal([]byte(`{"name": "Example"}`), actual))
+}
+
+func TestArtifactJSON(t *testing.T) {
+	// preparations
+	pkg := NewArtifact(ArtifactPkg, "Example.pkg")
+	pkg.AllowUntrusted = true
+
+	// test
+	data, err := json.Marshal(pkg)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"pkg": ["Example.pkg", {"allow_untrusted": true}]}`, string(data))
+
+	var actual Artifact
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, *pkg, actual)
+
+	// test (errors)
+	errs := map[string]string{
+		`{"zap": [{"trash": "~/Library/Example"}]}`: "unsupported artifact type 'zap'",
+		`{"app": []}`:                  "app artifact has no value",
+		`{"app": ["a"], "pkg": ["b"]}`: "expected a single artifact type, found 2",
+	}
+
+	for data, expected := range errs {
+		err := json.Unmarshal([]byte(data), &actual)
+		assert.EqualError(t, err, expected, data)
+	}
+}
+
+func TestLivecheckJSON(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("livecheck.rb")))
+	assert.Nil(t, c.Parse())
+	l := c.Variants[0].Livecheck
+
+	// test
+	data, err := json.Marshal(l)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"url":":homepage","strategy":"page_match"`)
+	assert.Contains(t, string(data), `"regex_flags":"i"`)
+
+	var actual Livecheck
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, l.Arguments(), actual.Arguments())
+
+	data, err = json.Marshal(NewLivecheck("https://example.com/"))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"url": "https://example.com/", "strategy": null, "regex": null, "strategy_block": null}`, string(data))
+
+	// test (unknown strategy)
+	actual = Livecheck{UnknownStrategy: "unknown"}
+	data, err = json.Marshal(actual)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"strategy":"unknown"`)
+
+	actual = Livecheck{}
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, StrategyDefault, actual.Strategy)
+	assert.Equal(t, "unknown", actual.UnknownStrategy)
+}
+
+func TestLifecycleEventJSON(t *testing.T) {
+	// preparations
+	e := NewLifecycleEvent(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), ReasonUnsigned)
+
+	// test
+	data, err := json.Marshal(e)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"date": "2023-01-01", "reason": "unsigned"}`, string(data))
+
+	var actual LifecycleEvent
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, *e, actual)
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"date": null, "reason": "it's broken"}`), &actual))
+	assert.Equal(t, LifecycleEvent{Message: "it's broken"}, actual)
+
+	// test (error)
+	err = json.Unmarshal([]byte(`{"date": "2023"}`), &actual)
+	assert.EqualError(t, err, "lifecycle date '2023' is invalid")
+}
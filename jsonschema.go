@@ -0,0 +1,255 @@
+package cask
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// jsonSchemaDialect specifies the JSON Schema dialect of the JSONSchema
+// document.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// A jsonSchemaer is implemented by the types with a custom JSON encoding that
+// can't be reflected.
+type jsonSchemaer interface {
+	jsonSchema(r *jsonSchemaReflector) map[string]interface{}
+}
+
+// jsonSchemaEnums specify the allowed string values referenced by the
+// "jsonschema" struct tags.
+var jsonSchemaEnums = map[string][]string{
+	"arch":  archSymbols[1:],
+	"macos": macOSSymbols[:],
+}
+
+var (
+	jsonSchemaerType = reflect.TypeOf((*jsonSchemaer)(nil)).Elem()
+	rawMessageType   = reflect.TypeOf(json.RawMessage{})
+)
+
+// A jsonSchemaReflector generates the JSON Schema from the Go types using the
+// same rules as the encoding/json package.
+type jsonSchemaReflector struct {
+	// defs specify the schemas of the named struct types referenced from the
+	// "$defs".
+	defs map[string]interface{}
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) document describing the
+// JSON encoding of the Cask. It's generated from the encoded types using
+// reflection, so it always matches the MarshalJSON output. Its "$defs" also
+// describe the JSON encodings of the Variant ("Variant"), Artifact
+// ("Artifact"), Livecheck ("Livecheck") and LifecycleEvent ("Lifecycle").
+func JSONSchema() ([]byte, error) {
+	r := &jsonSchemaReflector{defs: make(map[string]interface{})}
+
+	schema := r.reflect(reflect.TypeOf(caskJSON{}))
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = "Homebrew-Cask cask"
+
+	// the types that aren't referenced by the Cask encoding are still described
+	r.reflect(reflect.TypeOf(VariantSchema{}))
+	r.reflect(reflect.TypeOf(LifecycleSchema{}))
+
+	schema["$defs"] = r.defs
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// reflect returns the JSON Schema of the provided type. The exported struct
+// types are added to the jsonSchemaReflector.defs and referenced.
+func (r *jsonSchemaReflector) reflect(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return r.reflect(t.Elem())
+	}
+
+	if t.Implements(jsonSchemaerType) {
+		return reflect.Zero(t).Interface().(jsonSchemaer).jsonSchema(r)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t == rawMessageType {
+			return map[string]interface{}{}
+		}
+
+		return map[string]interface{}{"type": "array", "items": r.reflect(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.reflect(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" || !isExported(t.Name()) {
+			return r.object(t)
+		}
+
+		return r.ref(strings.TrimSuffix(t.Name(), "Schema"), func() map[string]interface{} {
+			return r.object(t)
+		})
+	}
+
+	return map[string]interface{}{}
+}
+
+// ref adds the schema returned by the provided function to the
+// jsonSchemaReflector.defs under the provided name, unless it's already there,
+// and returns the reference to it.
+func (r *jsonSchemaReflector) ref(name string, schema func() map[string]interface{}) map[string]interface{} {
+	if _, ok := r.defs[name]; !ok {
+		// the recursive references are resolved to the placeholder
+		r.defs[name] = nil
+		r.defs[name] = schema()
+	}
+
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+// object returns the JSON Schema of the provided struct type. The fields of
+// the embedded structs are flattened and only the fields without the
+// "omitempty" option are required. The pointer fields without the
+// "omitempty" option are nullable.
+func (r *jsonSchemaReflector) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+
+	r.fields(t, properties, &required)
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// fields adds the JSON Schemas of the provided struct type fields to the
+// properties and the names of the required ones to the required.
+func (r *jsonSchemaReflector) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, options = tag[:i], tag[i+1:]
+		}
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			r.fields(f.Type, properties, required)
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		schema := r.reflect(f.Type)
+		if enum, ok := jsonSchemaEnums[f.Tag.Get("jsonschema")]; ok {
+			schema["enum"] = jsonSchemaEnum(enum)
+		}
+
+		omitEmpty := strings.Contains(options, "omitempty")
+		if f.Type.Kind() == reflect.Ptr && !omitEmpty {
+			schema = nullable(schema)
+		}
+
+		properties[name] = schema
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable returns the provided JSON Schema which also allows null.
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if t, ok := schema["type"].(string); ok {
+		schema["type"] = []string{t, "null"}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			schema["enum"] = append(enum, nil)
+		}
+
+		return schema
+	}
+
+	return map[string]interface{}{
+		"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+	}
+}
+
+// jsonSchemaEnum returns the JSON Schema "enum" keyword value with the provided
+// strings.
+func jsonSchemaEnum(values []string) []interface{} {
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+
+	return enum
+}
+
+// isExported checks whether the provided Go identifier is exported.
+func isExported(name string) bool {
+	return name != "" && strings.ToUpper(name[:1]) == name[:1]
+}
+
+// jsonSchema returns the JSON Schema of the ArtifactSchema encoded in the
+// Homebrew-Cask JSON API shape.
+func (ArtifactSchema) jsonSchema(r *jsonSchemaReflector) map[string]interface{} {
+	return r.ref("Artifact", func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":          "object",
+			"minProperties": 1,
+			"maxProperties": 1,
+			"propertyNames": map[string]interface{}{"enum": jsonSchemaEnum(artifactTypeNames[:])},
+			"additionalProperties": map[string]interface{}{
+				"type":     "array",
+				"minItems": 1,
+				"maxItems": 2,
+				"prefixItems": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"target":          map[string]interface{}{"type": "string"},
+							"allow_untrusted": map[string]interface{}{"type": "boolean"},
+						},
+						"additionalProperties": false,
+					},
+				},
+			},
+		}
+	})
+}
+
+// jsonSchema returns the JSON Schema of the variations, which are the partial
+// Variant encodings keyed by the macOS release symbols.
+func (variationsJSON) jsonSchema(r *jsonSchemaReflector) map[string]interface{} {
+	variation := r.ref("Variation", func() map[string]interface{} {
+		schema := r.object(reflect.TypeOf(VariantSchema{}))
+		schema["required"] = []string{}
+
+		return schema
+	})
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"propertyNames":        map[string]interface{}{"enum": jsonSchemaEnum(jsonSchemaEnums["macos"])},
+		"additionalProperties": variation,
+	}
+}
@@ -0,0 +1,297 @@
+package cask
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSchemaValidator validates JSON values against the subset of the JSON
+// Schema keywords used by the JSONSchema.
+type testSchemaValidator struct {
+	root map[string]interface{}
+}
+
+func newTestSchemaValidator(t *testing.T) *testSchemaValidator {
+	data, err := JSONSchema()
+	assert.Nil(t, err)
+
+	v := new(testSchemaValidator)
+	assert.Nil(t, json.Unmarshal(data, &v.root))
+
+	return v
+}
+
+// validate validates the JSON encoding of the provided value against the
+// schema referenced by the provided definition name or the root schema if the
+// name is empty.
+func (v *testSchemaValidator) validate(value interface{}, def string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	schema := v.root
+	if def != "" {
+		schema = map[string]interface{}{"$ref": "#/$defs/" + def}
+	}
+
+	return v.check(schema, decoded, "$")
+}
+
+func (v *testSchemaValidator) check(schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		def, ok := v.root["$defs"].(map[string]interface{})[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unresolved reference %s", path, ref)
+		}
+
+		return v.check(def, value, path)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for _, s := range anyOf {
+			if v.check(s.(map[string]interface{}), value, path) == nil {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%s: no anyOf schema matches", path)
+	}
+
+	if err := checkType(schema["type"], value, path); err != nil {
+		return err
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || reflect.DeepEqual(e, value)
+		}
+
+		if !found {
+			return fmt.Errorf("%s: %v is not allowed", path, value)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return v.checkObject(schema, value, path)
+	case []interface{}:
+		return v.checkArray(schema, value, path)
+	}
+
+	return nil
+}
+
+func (v *testSchemaValidator) checkObject(schema map[string]interface{}, value map[string]interface{}, path string) error {
+	if min, ok := schema["minProperties"].(float64); ok && len(value) < int(min) {
+		return fmt.Errorf("%s: too few properties", path)
+	}
+
+	if max, ok := schema["maxProperties"].(float64); ok && len(value) > int(max) {
+		return fmt.Errorf("%s: too many properties", path)
+	}
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %s", path, name)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, item := range value {
+		if names, ok := schema["propertyNames"].(map[string]interface{}); ok {
+			if err := v.check(names, name, path+"."+name); err != nil {
+				return err
+			}
+		}
+
+		var s map[string]interface{}
+		if p, ok := properties[name]; ok {
+			s = p.(map[string]interface{})
+		} else {
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: unexpected property %s", path, name)
+				}
+			case map[string]interface{}:
+				s = additional
+			}
+		}
+
+		if s != nil {
+			if err := v.check(s, item, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *testSchemaValidator) checkArray(schema map[string]interface{}, value []interface{}, path string) error {
+	if min, ok := schema["minItems"].(float64); ok && len(value) < int(min) {
+		return fmt.Errorf("%s: too few items", path)
+	}
+
+	if max, ok := schema["maxItems"].(float64); ok && len(value) > int(max) {
+		return fmt.Errorf("%s: too many items", path)
+	}
+
+	prefixItems, _ := schema["prefixItems"].([]interface{})
+	for i, item := range value {
+		var s map[string]interface{}
+		if i < len(prefixItems) {
+			s = prefixItems[i].(map[string]interface{})
+		} else if items, ok := schema["items"].(map[string]interface{}); ok {
+			s = items
+		}
+
+		if s != nil {
+			if err := v.check(s, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkType(t interface{}, value interface{}, path string) error {
+	var types []interface{}
+	switch t := t.(type) {
+	case nil:
+		return nil
+	case string:
+		types = []interface{}{t}
+	case []interface{}:
+		types = t
+	}
+
+	actual := "null"
+	switch value.(type) {
+	case string:
+		actual = "string"
+	case bool:
+		actual = "boolean"
+	case float64:
+		actual = "number"
+	case []interface{}:
+		actual = "array"
+	case map[string]interface{}:
+		actual = "object"
+	}
+
+	for _, t := range types {
+		if t == actual || (t == "integer" && actual == "number") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: expected %v, got %s", path, types, actual)
+}
+
+func TestJSONSchema(t *testing.T) {
+	// preparations
+	data, err := JSONSchema()
+	assert.Nil(t, err)
+
+	var actual map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &actual))
+
+	defs := actual["$defs"].(map[string]interface{})
+
+	// test
+	assert.Equal(t, jsonSchemaDialect, actual["$schema"])
+	assert.Equal(t, "object", actual["type"])
+	assert.Contains(t, actual["required"], "token")
+	assert.Contains(t, actual["required"], "variations")
+	assert.NotContains(t, actual["required"], "minimum_macos")
+
+	for _, name := range []string{"Variant", "Variation", "Artifact", "Livecheck", "Lifecycle"} {
+		assert.Contains(t, defs, name)
+	}
+
+	properties := defs["Variant"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": []interface{}{"string", "null"}}, properties["url"])
+	assert.Equal(t, map[string]interface{}{"$ref": "#/$defs/Livecheck"}, properties["livecheck"])
+	assert.Equal(t, map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"$ref": "#/$defs/Artifact"},
+	}, properties["artifacts"])
+	assert.Len(t, properties["minimum_macos"].(map[string]interface{})["enum"], len(macOSSymbols))
+
+	// the schema is stable
+	again, err := JSONSchema()
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+func TestJSONSchemaValidation(t *testing.T) {
+	// preparations
+	v := newTestSchemaValidator(t)
+
+	// test
+	for _, filename := range []string{
+		"deprecated.rb",
+		"example-one.rb",
+		"if-global-sha256-last.rb",
+		"if-six-versions-six-appcasts.rb",
+		"if-three-versions-one-appcast.rb",
+		"latest.rb",
+		"livecheck.rb",
+	} {
+		c := NewCask(string(getTestdata(filename)))
+		assert.Nil(t, c.Parse(), filename)
+		assert.Nil(t, v.validate(c, ""), filename)
+
+		for _, variant := range c.Variants {
+			assert.Nil(t, v.validate(variant, "Variant"), filename)
+
+			if variant.Livecheck != nil {
+				assert.Nil(t, v.validate(variant.Livecheck, "Livecheck"), filename)
+			}
+
+			for _, a := range variant.Artifacts {
+				assert.Nil(t, v.validate(a, "Artifact"), filename)
+			}
+		}
+
+		if c.Lifecycle.Deprecated != nil {
+			assert.Nil(t, v.validate(c.Lifecycle.Deprecated, "Lifecycle"), filename)
+		}
+	}
+
+	assert.Nil(t, v.validate(NewCask(""), ""))
+
+	// test (invalid)
+	invalid := map[string]interface{}{
+		"$: missing required property token": map[string]interface{}{},
+		"$.artifacts[0]: too many properties": map[string]interface{}{
+			"artifacts": []interface{}{map[string]interface{}{"app": []string{"a"}, "pkg": []string{"b"}}},
+		},
+	}
+
+	defs := map[string]string{
+		"$: missing required property token":  "",
+		"$.artifacts[0]: too many properties": "Variation",
+	}
+
+	for expected, value := range invalid {
+		assert.EqualError(t, v.validate(value, defs[expected]), expected)
+	}
+}
@@ -33,6 +33,10 @@ type Lexer struct {
 	// width specifies the width of the last rune read from input.
 	width int
 
+	// previous specifies the type of the last emitted token. It's used to
+	// tell the regular expression literals from the divisions.
+	previous TokenType
+
 	// tokens specifies the channel of scanned tokens.
 	tokens chan Token
 }
@@ -59,7 +63,8 @@ func NewLexer(input string) *Lexer {
 	}
 }
 
-// NextToken will return the next token processed from the lexer.
+// NextToken will return the next token processed from the lexer. If there are
+// no tokens left, the EOF token is returned.
 func (l *Lexer) NextToken() Token {
 	for {
 		select {
@@ -67,7 +72,7 @@ func (l *Lexer) NextToken() Token {
 			if ok {
 				return item
 			}
-			panic(fmt.Errorf("No tokens left"))
+			return *NewToken(EOF, "", len(l.input))
 		default:
 			l.state = l.state(l)
 			if l.state == nil {
@@ -86,6 +91,7 @@ func (l *Lexer) HasNext() bool {
 func (l *Lexer) emit(t TokenType) {
 	l.tokens <- *NewToken(t, l.input[l.start:l.position], l.start)
 	l.start = l.position
+	l.previous = t
 }
 
 // next returns the next rune in the input.
@@ -185,6 +191,11 @@ func startLexer(l *Lexer) StateFn {
 
 		return startLexer
 	case '/':
+		if l.isRegexpStart() {
+			l.emit(PNSTART)
+			return lexRegexpContent('/')
+		}
+
 		l.emit(SLASH)
 		return startLexer
 	case '%':
@@ -250,6 +261,14 @@ func startLexer(l *Lexer) StateFn {
 	case '|':
 		l.emit(PIPE)
 		return startLexer
+	case '&':
+		if l.peek() == '&' {
+			l.next()
+			l.emit(AND)
+			return startLexer
+		}
+
+		return l.errorf("Illegal character at %d: '%c'", l.start, r)
 	default:
 		if isLetter(r) {
 			return lexIdentifier
@@ -298,6 +317,10 @@ func lexSingleQuoteString(l *Lexer) StateFn {
 	r := l.next()
 
 	for r != '\'' {
+		if r == eof {
+			return l.errorf("Unterminated string at %d", l.start-1)
+		}
+
 		// we skip the escape character
 		if l.peek() == '\'' && r == '\\' {
 			l.next()
@@ -320,6 +343,10 @@ func lexString(l *Lexer) StateFn {
 	r := l.next()
 
 	for r != '"' {
+		if r == eof {
+			return l.errorf("Unterminated string at %d", l.start-1)
+		}
+
 		// we skip the escape character
 		if l.peek() == '"' && r == '\\' {
 			l.next()
@@ -336,52 +363,95 @@ func lexString(l *Lexer) StateFn {
 	return startLexer
 }
 
-// lexRegexp lexes the regular expression.
+// lexRegexp lexes the regular expression in the percent notation.
 func lexRegexp(l *Lexer) StateFn {
 	l.ignore()
 
 	r := l.next()
+	if r == eof {
+		return l.errorf("Unterminated regular expression at %d", l.start)
+	}
+
 	l.emit(PNSTART)
 
-	pnStart := r
+	return lexRegexpContent(r)
+}
+
+// lexRegexpContent returns the StateFn that lexes the regular expression
+// content and the closing delimiter matching the provided opening one. The
+// escaped characters and the nested paired delimiters are skipped.
+func lexRegexpContent(pnStart rune) StateFn {
 	pnEnd := pnStart
 
 	switch pnStart {
 	case '(':
 		pnEnd = ')'
-		break
 	case '[':
 		pnEnd = ']'
-		break
 	case '{':
 		pnEnd = '}'
-		break
 	case '<':
 		pnEnd = '>'
-		break
 	}
 
-	for r != pnEnd {
-		r = l.next()
+	return func(l *Lexer) StateFn {
+		depth := 0
+
+		for {
+			r := l.next()
+
+			switch {
+			case r == eof:
+				return l.errorf("Unterminated regular expression at %d", l.start)
+			case r == '\\':
+				l.next()
+			case r == pnEnd && depth == 0:
+				l.backup()
+				l.emit(REGEXP)
+				l.next()
+				l.emit(PNEND)
+
+				return startLexer
+			case r == pnEnd:
+				depth--
+			case r == pnStart:
+				depth++
+			}
+		}
 	}
+}
 
-	l.backup()
-	l.emit(REGEXP)
-	l.next()
-	l.emit(PNEND)
+// isRegexpStart checks whether the "/" character that has just been read
+// starts a regular expression literal instead of being a division operator.
+// Like in Ruby, it does after the operators, the opening brackets and at the
+// statement start. After an identifier, it does only when it's preceded by a
+// whitespace and isn't followed by one (for example, "regex /[0-9]+/").
+func (l *Lexer) isRegexpStart() bool {
+	switch l.previous {
+	case EOF, NEWLINE, SEMICOLON, COMMA, LPAREN, LBRACKET, LBRACE, PIPE, ASSIGN, EQ, NOTEQ, IF, ELSEIF, RETURN:
+		return true
+	case IDENT:
+		before, _ := utf8.DecodeLastRuneInString(l.input[:l.start])
+		return isWhitespace(before) && !unicode.IsSpace(l.peek())
+	}
 
-	return startLexer
+	return false
 }
 
 // lexHeredoc lexes the heredoc expression.
 func lexHeredoc(l *Lexer) StateFn {
 	l.ignore()
+	start := l.start
 
 	// HEREDOCSTART
 	r := l.next()
 	hdStart := string(r)
 
 	for r != '\n' {
+		if r == eof {
+			return l.errorf("Unterminated heredoc at %d", start)
+		}
+
 		r = l.next()
 		hdStart += string(r)
 	}
@@ -393,7 +463,9 @@ func lexHeredoc(l *Lexer) StateFn {
 	l.next()
 
 	for !l.isEndingWithString(hdStart) {
-		l.next()
+		if l.next() == eof {
+			return l.errorf("Unterminated heredoc at %d", start)
+		}
 	}
 
 	l.position -= len(hdStart)
@@ -455,13 +527,18 @@ func lexGlobal(l *Lexer) StateFn {
 	return startLexer
 }
 
-// commentLexer lexes the comment.
+// commentLexer lexes the comment. The comment is ignored, but the newline
+// character ending it is lexed as usual.
 func commentLexer(l *Lexer) StateFn {
 	r := l.next()
-	for r != '\n' {
+	for r != '\n' && r != eof {
 		r = l.next()
 	}
 
+	if r == '\n' {
+		l.backup()
+	}
+
 	l.ignore()
 
 	return startLexer
@@ -66,6 +66,7 @@ result = add(five, fifty)
 10 != 9
 4 % 2
 5 < 10 > 5
+true && false
 
 [1, 2]
 A::B
@@ -110,6 +111,7 @@ add { |x| x }
 	}{
 		{NEWLINE, "\n"},
 		{NEWLINE, "\n"},
+		{NEWLINE, "\n"},
 
 		{IDENT, "five"},
 		{ASSIGN, "="},
@@ -161,6 +163,11 @@ add { |x| x }
 		{GT, ">"},
 		{INT, "5"},
 		{NEWLINE, "\n"},
+
+		{TRUE, "true"},
+		{AND, "&&"},
+		{FALSE, "false"},
+		{NEWLINE, "\n"},
 		{NEWLINE, "\n"},
 
 		{LBRACKET, "["},
@@ -290,6 +297,7 @@ add { |x| x }
 	assertSingleNextToken(t, "$ ", ILLEGAL, "Illegal character at 2: ' '")
 	assertSingleNextToken(t, "$;", ILLEGAL, "Illegal character at 2: ';'")
 	assertSingleNextToken(t, "\\", ILLEGAL, "Illegal character at 0: '\\'")
+	assertSingleNextToken(t, "&", ILLEGAL, "Illegal character at 0: '&'")
 }
 
 func TestLexerPercentNotationRegexp(t *testing.T) {
@@ -299,6 +307,7 @@ func TestLexerPercentNotationRegexp(t *testing.T) {
 %r[regex]
 %r{regex}
 %r<regex>
+%r|regex|
 `
 
 	testCases := []struct {
@@ -331,6 +340,62 @@ func TestLexerPercentNotationRegexp(t *testing.T) {
 		{PNEND, ">"},
 		{NEWLINE, "\n"},
 
+		{PNREGEXP, "%r"},
+		{PNSTART, "|"},
+		{REGEXP, "regex"},
+		{PNEND, "|"},
+		{NEWLINE, "\n"},
+
+		{EOF, ""},
+	}
+
+	lexer := NewLexer(input)
+
+	// test
+	for position, testCase := range testCases {
+		assertNextToken(t, lexer, testCase.expectedType, testCase.expectedLiteral, position)
+	}
+}
+
+func TestLexerRegexp(t *testing.T) {
+	// test (successful)
+	input := `regex(/href=.*?app[._-]v?(\d+(?:\.\d+)+)\.dmg/i)
+regex %r{/app-(\d{2}\.\d+)\.dmg}
+regex /\/# "'/
+a / b
+`
+
+	testCases := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+	}{
+		{IDENT, "regex"},
+		{LPAREN, "("},
+		{PNSTART, "/"},
+		{REGEXP, `href=.*?app[._-]v?(\d+(?:\.\d+)+)\.dmg`},
+		{PNEND, "/"},
+		{IDENT, "i"},
+		{RPAREN, ")"},
+		{NEWLINE, "\n"},
+
+		{IDENT, "regex"},
+		{PNREGEXP, "%r"},
+		{PNSTART, "{"},
+		{REGEXP, `/app-(\d{2}\.\d+)\.dmg`},
+		{PNEND, "}"},
+		{NEWLINE, "\n"},
+
+		{IDENT, "regex"},
+		{PNSTART, "/"},
+		{REGEXP, `\/# "'`},
+		{PNEND, "/"},
+		{NEWLINE, "\n"},
+
+		{IDENT, "a"},
+		{SLASH, "/"},
+		{IDENT, "b"},
+		{NEWLINE, "\n"},
+
 		{EOF, ""},
 	}
 
@@ -340,6 +405,13 @@ func TestLexerPercentNotationRegexp(t *testing.T) {
 	for position, testCase := range testCases {
 		assertNextToken(t, lexer, testCase.expectedType, testCase.expectedLiteral, position)
 	}
+
+	// test (error)
+	lexer = NewLexer("regex(/unterminated)")
+	lexer.NextToken()
+	lexer.NextToken()
+	lexer.NextToken()
+	assert.Equal(t, *NewToken(ILLEGAL, "Unterminated regular expression at 7", 7), lexer.NextToken())
 }
 
 func TestLexerHeredoc(t *testing.T) {
@@ -440,6 +512,60 @@ func TestLexerStrings(t *testing.T) {
 	}
 }
 
+func TestLexerUnterminated(t *testing.T) {
+	testCases := map[string]string{
+		"'string":           "Unterminated string at 0",
+		`"string`:           "Unterminated string at 0",
+		"%r{regex":          "Unterminated regular expression at 3",
+		"%r":                "Unterminated regular expression at 2",
+		"<<~EOS":            "Unterminated heredoc at 3",
+		"<<~EOS\ntext\n":    "Unterminated heredoc at 3",
+		"<<~EOS\ntext\nEOS": "Unterminated heredoc at 3",
+	}
+
+	for input, expected := range testCases {
+		// preparations
+		lexer := NewLexer(input)
+
+		// test
+		token := lexer.NextToken()
+		for token.Type != ILLEGAL && token.Type != EOF {
+			token = lexer.NextToken()
+		}
+
+		assert.Equal(t, ILLEGAL, token.Type, input)
+		assert.Equal(t, expected, token.Literal, input)
+		assert.False(t, lexer.HasNext())
+
+		// no tokens left
+		assertNextToken(t, lexer, EOF, "", 0)
+		assertNextToken(t, lexer, EOF, "", 0)
+	}
+}
+
+func TestLexerCommentWithoutNewline(t *testing.T) {
+	// preparations
+	lexer := NewLexer("five = 5 # comment")
+
+	// test
+	assertNextToken(t, lexer, IDENT, "five", 0)
+	assertNextToken(t, lexer, ASSIGN, "=", 1)
+	assertNextToken(t, lexer, INT, "5", 2)
+	assertNextToken(t, lexer, EOF, "", 3)
+}
+
+func TestLexerCommentWithNewline(t *testing.T) {
+	// preparations
+	lexer := NewLexer("five = 5 # comment\nten = 10")
+
+	// test
+	assertNextToken(t, lexer, IDENT, "five", 0)
+	assertNextToken(t, lexer, ASSIGN, "=", 1)
+	assertNextToken(t, lexer, INT, "5", 2)
+	assertNextToken(t, lexer, NEWLINE, "\n", 3)
+	assertNextToken(t, lexer, IDENT, "ten", 4)
+}
+
 func TestLexerDelimiters(t *testing.T) {
 	assertSingleNextToken(t, ",", COMMA, ",")
 	assertSingleNextToken(t, "\n", NEWLINE, "\n")
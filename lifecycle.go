@@ -0,0 +1,164 @@
+package cask
+
+import (
+	"strings"
+	"time"
+)
+
+// A LifecycleReason represents the reason of the cask deprecation or
+// disabling.
+type LifecycleReason int
+
+// Different lifecycle reasons. The ReasonCustom is used for the free-form
+// reasons stored in the LifecycleEvent.Message.
+const (
+	ReasonCustom LifecycleReason = iota
+	ReasonDiscontinued
+	ReasonMovedToMAS
+	ReasonNoLongerAvailable
+	ReasonNoLongerMeetsCriteria
+	ReasonUnmaintained
+	ReasonUnsigned
+	ReasonFailsGatekeeperCheck
+)
+
+var lifecycleReasonSymbols = [...]string{
+	"",
+	"discontinued",
+	"moved_to_mas",
+	"no_longer_available",
+	"no_longer_meets_criteria",
+	"unmaintained",
+	"unsigned",
+	"fails_gatekeeper_check",
+}
+
+// lifecycleDateLayout specifies the date layout used in the "deprecate!" and
+// "disable!" stanzas.
+const lifecycleDateLayout = "2006-01-02"
+
+// A LifecycleEvent represents a "deprecate!" or "disable!" cask stanza.
+type LifecycleEvent struct {
+	// Date specifies the date since which the event is in effect. The zero
+	// value means that it's in effect since the beginning.
+	Date time.Time
+
+	// Reason specifies the known reason of the event.
+	Reason LifecycleReason
+
+	// Message specifies the free-form reason. It's only set when the
+	// LifecycleEvent.Reason is ReasonCustom.
+	Message string
+}
+
+// A Lifecycle represents the cask deprecation and disabling specified by the
+// "deprecate!" and "disable!" stanzas.
+type Lifecycle struct {
+	// Deprecated specifies the "deprecate!" stanza. It's nil if the cask isn't
+	// deprecated.
+	Deprecated *LifecycleEvent
+
+	// Disabled specifies the "disable!" stanza. It's nil if the cask isn't
+	// disabled.
+	Disabled *LifecycleEvent
+}
+
+// lifecycleReasonFromSymbol returns the LifecycleReason matching the provided
+// Ruby symbol name. The second value reports whether the reason was found.
+func lifecycleReasonFromSymbol(symbol string) (LifecycleReason, bool) {
+	for i, s := range lifecycleReasonSymbols {
+		if i > 0 && s == symbol {
+			return LifecycleReason(i), true
+		}
+	}
+
+	return ReasonCustom, false
+}
+
+// Symbol returns the LifecycleReason Ruby symbol name. Returns an empty string
+// for the ReasonCustom.
+func (r LifecycleReason) Symbol() string {
+	return lifecycleReasonSymbols[r]
+}
+
+// String returns the string representation of the LifecycleReason, which is
+// its Ruby symbol name or "custom" for the ReasonCustom.
+func (r LifecycleReason) String() string {
+	if r == ReasonCustom {
+		return "custom"
+	}
+
+	return r.Symbol()
+}
+
+// NewLifecycleEvent creates a new LifecycleEvent instance and returns its
+// pointer. Requires both LifecycleEvent.Date and LifecycleEvent.Reason to be
+// passed as arguments. For the free-form reasons, the ReasonCustom should be
+// used together with the LifecycleEvent.Message.
+func NewLifecycleEvent(date time.Time, reason LifecycleReason) *LifecycleEvent {
+	return &LifecycleEvent{Date: date, Reason: reason}
+}
+
+// IsActive checks whether the LifecycleEvent is in effect as of the provided
+// time.
+func (e LifecycleEvent) IsActive(at time.Time) bool {
+	return e.Date.IsZero() || !at.Before(e.Date)
+}
+
+// String returns a string representation of the LifecycleEvent struct which is
+// its reason.
+func (e LifecycleEvent) String() string {
+	if e.Reason == ReasonCustom {
+		return e.Message
+	}
+
+	return e.Reason.String()
+}
+
+// Arguments returns the LifecycleEvent Ruby stanza arguments.
+func (e LifecycleEvent) Arguments() string {
+	var args []string
+
+	if !e.Date.IsZero() {
+		args = append(args, "date: "+rubyString(e.Date.Format(lifecycleDateLayout)))
+	}
+
+	switch {
+	case e.Reason != ReasonCustom:
+		args = append(args, "because: :"+e.Reason.Symbol())
+	case e.Message != "":
+		args = append(args, "because: "+rubyString(e.Message))
+	}
+
+	return strings.Join(args, ", ")
+}
+
+// IsDeprecated checks whether the cask is deprecated as of the provided time.
+// Similar to Homebrew-Cask, the cask that will be disabled in the future is
+// already considered deprecated.
+func (l Lifecycle) IsDeprecated(at time.Time) bool {
+	if l.Deprecated != nil && l.Deprecated.IsActive(at) {
+		return true
+	}
+
+	return l.Disabled != nil && !l.Disabled.IsActive(at)
+}
+
+// IsDisabled checks whether the cask is disabled as of the provided time.
+func (l Lifecycle) IsDisabled(at time.Time) bool {
+	return l.Disabled != nil && l.Disabled.IsActive(at)
+}
+
+// formatted returns the "deprecate!" and "disable!" stanzas written as Ruby
+// code lines.
+func (l Lifecycle) formatted() (result formattedStanzas) {
+	if l.Deprecated != nil {
+		result = append(result, formattedStanza{"deprecate!", "deprecate! " + l.Deprecated.Arguments()})
+	}
+
+	if l.Disabled != nil {
+		result = append(result, formattedStanza{"disable!", "disable! " + l.Disabled.Arguments()})
+	}
+
+	return result
+}
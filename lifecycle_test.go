@@ -0,0 +1,82 @@
+package cask
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleReason(t *testing.T) {
+	// test
+	assert.Equal(t, "discontinued", ReasonDiscontinued.String())
+	assert.Equal(t, "fails_gatekeeper_check", ReasonFailsGatekeeperCheck.Symbol())
+	assert.Equal(t, "custom", ReasonCustom.String())
+	assert.Empty(t, ReasonCustom.Symbol())
+
+	reason, ok := lifecycleReasonFromSymbol("moved_to_mas")
+	assert.True(t, ok)
+	assert.Equal(t, ReasonMovedToMAS, reason)
+
+	reason, ok = lifecycleReasonFromSymbol("")
+	assert.False(t, ok)
+	assert.Equal(t, ReasonCustom, reason)
+}
+
+func TestLifecycleEvent(t *testing.T) {
+	// preparations
+	date := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewLifecycleEvent(date, ReasonUnmaintained)
+
+	// test
+	assert.Equal(t, "unmaintained", e.String())
+	assert.Equal(t, "date: '2023-01-01', because: :unmaintained", e.Arguments())
+	assert.False(t, e.IsActive(date.Add(-time.Second)))
+	assert.True(t, e.IsActive(date))
+
+	e = NewLifecycleEvent(time.Time{}, ReasonCustom)
+	e.Message = "it's broken"
+	assert.Equal(t, "it's broken", e.String())
+	assert.Equal(t, `because: "it's broken"`, e.Arguments())
+	assert.True(t, e.IsActive(date))
+}
+
+func TestLifecycle(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("deprecated.rb")))
+	assert.Nil(t, c.Parse())
+
+	before := time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)
+	deprecated := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	disabled := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// test
+	assert.Equal(t, &LifecycleEvent{
+		Date:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Reason: ReasonDiscontinued,
+	}, c.Lifecycle.Deprecated)
+	assert.Equal(t, &LifecycleEvent{
+		Date:    disabled,
+		Message: "is no longer maintained",
+	}, c.Lifecycle.Disabled)
+	assert.Empty(t, c.Warnings)
+	assert.Empty(t, c.Variants[0].Stanzas)
+
+	// a cask that will be disabled is already deprecated
+	assert.True(t, c.Lifecycle.IsDeprecated(before))
+	assert.False(t, c.Lifecycle.IsDisabled(before))
+	assert.True(t, c.Lifecycle.IsDeprecated(deprecated))
+	assert.False(t, c.Lifecycle.IsDisabled(deprecated))
+	assert.True(t, c.Lifecycle.IsDeprecated(disabled))
+	assert.True(t, c.Lifecycle.IsDisabled(disabled))
+
+	// test (deprecated only)
+	c.Lifecycle.Disabled = nil
+	assert.False(t, c.Lifecycle.IsDeprecated(before))
+	assert.True(t, c.Lifecycle.IsDeprecated(deprecated))
+	assert.False(t, c.Lifecycle.IsDisabled(disabled))
+
+	// test (none)
+	assert.False(t, Lifecycle{}.IsDeprecated(disabled))
+	assert.False(t, Lifecycle{}.IsDisabled(disabled))
+}
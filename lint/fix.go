@@ -0,0 +1,322 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/victorpopkov/go-cask"
+)
+
+// A Finding represents a single Problem found by the Fix together with the
+// information whether it has been fixed.
+type Finding struct {
+	Problem
+
+	// Fixed specifies whether the problem has been fixed.
+	Fixed bool
+}
+
+// A fixer represents the state shared by all fixes while fixing a single cask.
+type fixer struct {
+	// tree specifies the original cask content SyntaxTree.
+	tree *cask.SyntaxTree
+
+	// indexes specify the SyntaxTree.Tokens indexes of the tree tokens.
+	indexes map[*cask.SyntaxToken]int
+
+	// fixed specify the offsets of the fixed problems grouped by the rule ID.
+	fixed map[string]map[int]bool
+}
+
+// A chunk represents the source text of a single stanza moved while
+// reordering stanzas.
+type chunk struct {
+	start int
+	end   int
+	rank  int
+}
+
+// Fix lints the provided cask content and rewrites the fixable problems: the
+// stanza order, the quote style, the insecure URLs and the deprecated appcast
+// checkpoint arguments. Everything else in the content, including comments
+// and whitespaces, is preserved. Returns the fixed content and all found
+// problems with the positions in the original content. If the content can't
+// be tokenized, it's returned unchanged without findings.
+func Fix(content string) (string, []Finding) {
+	// the parsing errors are ignored, as the rules mostly rely on the
+	// SyntaxTree which is built anyway
+	c := cask.NewCask(content)
+	c.Parse()
+
+	problems, err := Lint(c)
+	if err != nil {
+		return content, nil
+	}
+
+	tree, _ := cask.NewSyntaxTree(content)
+	f := &fixer{
+		tree:    tree,
+		indexes: make(map[*cask.SyntaxToken]int),
+		fixed:   make(map[string]map[int]bool),
+	}
+
+	for i, t := range tree.Tokens {
+		f.indexes[t] = i
+	}
+
+	// the token fixes don't change the tokens structure, so the stanzas can be
+	// reordered afterwards by their indexes in the original tree
+	f.fixQuoteStyle()
+	f.fixInsecureURLs()
+	f.fixDeprecatedArguments()
+
+	result, reordered := reorderStanzas(tree.String())
+	for i, s := range tree.Stanzas {
+		if reordered[i] {
+			f.markFixed(RuleStanzaOrder, s.Tokens[0].Position)
+		}
+	}
+
+	findings := make([]Finding, len(problems))
+	for i, p := range problems {
+		findings[i] = Finding{
+			Problem: p,
+			Fixed:   f.fixed[p.Rule][p.Position.Offset],
+		}
+	}
+
+	return result, findings
+}
+
+// markFixed marks the problem reported by the specified rule at the specified
+// offset as fixed.
+func (f *fixer) markFixed(rule string, offset int) {
+	if f.fixed[rule] == nil {
+		f.fixed[rule] = make(map[int]bool)
+	}
+
+	f.fixed[rule][offset] = true
+}
+
+// closing returns the pointer to the source text following the specified
+// token, which starts with the closing string delimiter.
+func (f *fixer) closing(t *cask.SyntaxToken) *string {
+	if next := f.indexes[t] + 1; next < len(f.tree.Tokens) {
+		return &f.tree.Tokens[next].Leading
+	}
+
+	return &f.tree.Trailing
+}
+
+// fixQuoteStyle replaces the double quotes of the strings that don't need
+// interpolations or special symbols with the single ones.
+func (f *fixer) fixQuoteStyle() {
+	for _, t := range f.tree.Tokens {
+		if t.Type != cask.STRING || !strings.HasSuffix(t.Leading, `"`) {
+			continue
+		}
+
+		if strings.Contains(t.Literal, "#{") || strings.ContainsAny(t.Literal, `'\`) {
+			continue
+		}
+
+		closing := f.closing(t)
+		if !strings.HasPrefix(*closing, `"`) {
+			continue
+		}
+
+		t.Leading = strings.TrimSuffix(t.Leading, `"`) + "'"
+		*closing = "'" + (*closing)[1:]
+		f.markFixed(RuleQuoteStyle, t.Position-1)
+	}
+}
+
+// fixInsecureURLs replaces the http:// scheme of the url, appcast and homepage
+// stanza values with the https:// one.
+func (f *fixer) fixInsecureURLs() {
+	for _, name := range urlStanzas {
+		for _, s := range f.tree.FindStanzas(name) {
+			t := s.ValueToken()
+			if t != nil && strings.HasPrefix(t.Literal, "http://") {
+				t.Literal = "https://" + strings.TrimPrefix(t.Literal, "http://")
+				f.markFixed(RuleInsecureURL, t.Position)
+			}
+		}
+	}
+}
+
+// fixDeprecatedArguments removes the deprecated "checkpoint:" arguments of the
+// appcast stanzas. Only the last arguments in the form supported by the parser
+// are removed.
+func (f *fixer) fixDeprecatedArguments() {
+	for _, s := range f.tree.FindStanzas("appcast") {
+		i := checkpointIndex(s)
+		if i < 0 || i+2 != len(s.Tokens)-1 {
+			continue
+		}
+
+		if s.Tokens[i+1].Type != cask.SYMBOL || s.Tokens[i+2].Type != cask.STRING {
+			continue
+		}
+
+		// the comma can be followed by a newline
+		comma := i - 1
+		if s.Tokens[comma].Type == cask.NEWLINE {
+			comma--
+		}
+
+		if comma < 1 || s.Tokens[comma].Type != cask.COMMA {
+			continue
+		}
+
+		closing := f.closing(s.Tokens[i+2])
+		if *closing == "" {
+			continue
+		}
+
+		// the comma leading text ends with the closing delimiter of the previous
+		// argument, so it's kept
+		s.Tokens[comma].Literal = ""
+		for _, t := range s.Tokens[comma+1:] {
+			t.Leading = ""
+			t.Literal = ""
+		}
+		*closing = (*closing)[1:]
+
+		f.markFixed(RuleDeprecatedArgument, s.Tokens[i].Position)
+	}
+}
+
+// reorderStanzas sorts the sibling stanzas with the known names in the
+// canonical order. Each stanza is moved together with the comment lines
+// directly above it and the comment at the end of its last line, while the
+// unknown stanzas and other statements stay in place. The blocks with the
+// stanzas that can't be moved safely are left unchanged. Returns the
+// reordered content and the SyntaxTree.Stanzas indexes of the stanzas in the
+// reordered blocks.
+func reorderStanzas(content string) (string, map[int]bool) {
+	reordered := make(map[int]bool)
+
+	tree, err := cask.NewSyntaxTree(content)
+	if err != nil {
+		return content, reordered
+	}
+
+	rank := make(map[string]int)
+	for _, group := range cask.StanzaOrder() {
+		for _, name := range group {
+			rank[name] = len(rank)
+		}
+	}
+
+	indexes := make(map[*cask.SyntaxToken]int)
+	for i, t := range tree.Tokens {
+		indexes[t] = i
+	}
+
+	var blocks []int
+	chunks := make(map[int][]chunk)
+	stanzas := make(map[int][]int)
+	invalid := make(map[int]bool)
+
+	for i, s := range tree.Stanzas {
+		r, ok := rank[s.Name]
+		if !ok {
+			continue
+		}
+
+		if _, ok := chunks[s.Block]; !ok {
+			blocks = append(blocks, s.Block)
+		}
+
+		ch, ok := stanzaChunk(tree, indexes, s)
+		if !ok {
+			invalid[s.Block] = true
+		}
+
+		ch.rank = r
+		chunks[s.Block] = append(chunks[s.Block], ch)
+		stanzas[s.Block] = append(stanzas[s.Block], i)
+	}
+
+	type edit struct {
+		chunk
+		text string
+	}
+
+	var edits []edit
+	for _, block := range blocks {
+		slots := chunks[block]
+		if invalid[block] || sort.SliceIsSorted(slots, func(i, j int) bool {
+			return slots[i].rank < slots[j].rank
+		}) {
+			continue
+		}
+
+		// the nested blocks are left unchanged when their parent stanza moves
+		overlaps := false
+		for _, e := range edits {
+			if slots[0].start < e.end && e.start < slots[len(slots)-1].end {
+				overlaps = true
+				break
+			}
+		}
+
+		if overlaps {
+			continue
+		}
+
+		sorted := make([]chunk, len(slots))
+		copy(sorted, slots)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].rank < sorted[j].rank
+		})
+
+		for i, slot := range slots {
+			edits = append(edits, edit{slot, content[sorted[i].start:sorted[i].end]})
+		}
+
+		for _, i := range stanzas[block] {
+			reordered[i] = true
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+
+	for _, e := range edits {
+		content = content[:e.start] + e.text + content[e.end:]
+	}
+
+	return content, reordered
+}
+
+// stanzaChunk returns the chunk of the provided stanza. The stanza should be
+// the only statement on its lines. Returns false if it's not.
+func stanzaChunk(tree *cask.SyntaxTree, indexes map[*cask.SyntaxToken]int, s *cask.StanzaNode) (chunk, bool) {
+	first := indexes[s.Tokens[0]]
+	last := indexes[s.Tokens[len(s.Tokens)-1]]
+
+	if first == 0 || tree.Tokens[first-1].Type != cask.NEWLINE || strings.TrimSpace(s.Tokens[0].Leading) != "" {
+		return chunk{}, false
+	}
+
+	if last+1 >= len(tree.Tokens) || tree.Tokens[last+1].Type != cask.NEWLINE {
+		return chunk{}, false
+	}
+
+	ch := chunk{start: s.Tokens[0].Position, end: tree.Tokens[last+1].Position}
+
+	// the comment lines directly above the stanza are a part of it
+	for i := first - 1; i > 0; i-- {
+		comment := strings.TrimLeft(tree.Tokens[i].Leading, " \t")
+		if tree.Tokens[i].Type != cask.NEWLINE || tree.Tokens[i-1].Type != cask.NEWLINE || !strings.HasPrefix(comment, "#") {
+			break
+		}
+
+		ch.start = tree.Tokens[i].Position - len(comment)
+	}
+
+	return ch, true
+}
@@ -0,0 +1,181 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func findingStrings(findings []Finding) (result []string) {
+	for _, f := range findings {
+		status := "unfixed"
+		if f.Fixed {
+			status = "fixed"
+		}
+
+		result = append(result, status+": "+f.String())
+	}
+
+	return result
+}
+
+func TestFix(t *testing.T) {
+	// test
+	actual, findings := Fix(getTestdata("problems.rb"))
+	assert.Equal(t, `cask 'problems' do
+  version '1.0.0' 
+  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+  url 'https://example.com/app_1.0.0.dmg'
+  name 'Example'
+  name 'Example'
+  homepage "https://example.com/#{version}/"
+
+  app 'Example.app'
+end
+`, actual)
+	assert.Equal(t, []string{
+		"fixed: 3:3: warning: 'version' stanza should go before 'sha256' (stanza-order)",
+		"unfixed: 3:18: warning: trailing whitespace (trailing-whitespace)",
+		"fixed: 5:7: warning: prefer single-quoted strings when you don't need string interpolation or special symbols (quote-style)",
+		"fixed: 5:8: warning: url should use https:// instead of http:// (insecure-url)",
+		"unfixed: 7:9: warning: duplicated name 'Example' (duplicate-name)",
+		"fixed: 10:7: warning: prefer single-quoted strings when you don't need string interpolation or special symbols (quote-style)",
+	}, findingStrings(findings))
+
+	// test (idempotence)
+	again, _ := Fix(actual)
+	assert.Equal(t, actual, again)
+}
+
+func TestFixStanzaOrder(t *testing.T) {
+	// test
+	actual, findings := Fix(`# frozen_string_literal: true
+
+cask 'example' do # the example cask
+  if MacOS.version <= :leopard
+    version '1.0.0'
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+  else
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+    version '2.0.0' # latest version
+  end
+
+  # the download url
+  url "https://example.com/app_#{version}.dmg",
+      verified: 'example.com/'
+  homepage 'https://example.com/'
+  name 'Example'
+
+  app 'Example.app'
+
+  uninstall quit: 'com.example'
+
+  postflight do
+    set_permissions "#{appdir}/Example.app", '0755'
+  end
+end
+`)
+
+	assert.Equal(t, `# frozen_string_literal: true
+
+cask 'example' do # the example cask
+  if MacOS.version <= :leopard
+    version '1.0.0'
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+  else
+    version '2.0.0' # latest version
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+  end
+
+  # the download url
+  url "https://example.com/app_#{version}.dmg",
+      verified: 'example.com/'
+  name 'Example'
+  homepage 'https://example.com/'
+
+  app 'Example.app'
+
+  postflight do
+    set_permissions "#{appdir}/Example.app", '0755'
+  end
+
+  uninstall quit: 'com.example'
+end
+`, actual)
+	assert.Equal(t, []string{
+		"fixed: 9:5: warning: 'version' stanza should go before 'sha256' (stanza-order)",
+		"fixed: 16:3: warning: 'name' stanza should go before 'homepage' (stanza-order)",
+		"fixed: 22:3: warning: 'postflight' stanza should go before 'uninstall' (stanza-order)",
+	}, findingStrings(findings))
+
+	// test (comments)
+	actual, _ = Fix(`cask 'example' do
+  # the hash
+  sha256 :no_check
+  # the version
+  # (always latest)
+  version :latest
+end
+`)
+
+	assert.Equal(t, `cask 'example' do
+  # the version
+  # (always latest)
+  version :latest
+  # the hash
+  sha256 :no_check
+end
+`, actual)
+}
+
+func TestFixStanzaOrderUnsafe(t *testing.T) {
+	// preparations
+	content := `cask 'example' do
+  sha256 :no_check; version :latest
+
+  url 'https://example.com/app.dmg'
+  name 'Example'
+  homepage 'https://example.com/'
+end
+`
+
+	// test
+	actual, findings := Fix(content)
+	assert.Equal(t, content, actual)
+	assert.Equal(t, []string{
+		"unfixed: 2:21: warning: 'version' stanza should go before 'sha256' (stanza-order)",
+	}, findingStrings(findings))
+}
+
+func TestFixDeprecatedArguments(t *testing.T) {
+	testCases := map[string]string{
+		"appcast 'https://example.com/appcast.xml', checkpoint: 'abc'":                        "appcast 'https://example.com/appcast.xml'",
+		"appcast \"https://example.com/appcast.xml\",\n          checkpoint: 'abc' # comment": "appcast 'https://example.com/appcast.xml' # comment",
+		"appcast 'http://example.com/appcast.xml',\n          checkpoint: \"abc\"":            "appcast 'https://example.com/appcast.xml'",
+		"appcast 'https://example.com/#{version}.xml', checkpoint: 'abc'":                     "appcast 'https://example.com/#{version}.xml'",
+		"appcast 'https://example.com/appcast.xml', checkpoint: 'abc', foo: 'bar'":            "appcast 'https://example.com/appcast.xml', checkpoint: 'abc', foo: 'bar'",
+	}
+
+	for content, expected := range testCases {
+		// test
+		actual, findings := Fix("cask 'example' do\n  " + content + "\nend\n")
+		assert.Equal(t, "cask 'example' do\n  "+expected+"\nend\n", actual, content)
+
+		for _, f := range findings {
+			if f.Rule == RuleDeprecatedArgument {
+				assert.Equal(t, expected != content, f.Fixed, content)
+			}
+		}
+	}
+}
+
+func TestFixError(t *testing.T) {
+	// preparations
+	content := "cask 'example' do\n  version '1.0.0\nend\n"
+
+	// test
+	actual, findings := Fix(content)
+	assert.Equal(t, content, actual)
+	assert.Nil(t, findings)
+}
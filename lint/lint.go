@@ -0,0 +1,120 @@
+// Package lint checks the Homebrew-Cask casks for the stanza order and style
+// problems similar to the ones reported by "brew audit" and "brew style".
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/victorpopkov/go-cask"
+)
+
+// A Severity represents the Problem severity.
+type Severity int
+
+// Different problem severities.
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+var severityNames = [...]string{
+	"warning",
+	"error",
+}
+
+// String returns the string representation of the Severity.
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// A Problem represents a single problem found in the cask.
+type Problem struct {
+	// Rule specifies the ID of the rule that reported the problem.
+	Rule string
+
+	// Severity specifies the problem severity.
+	Severity Severity
+
+	// Message specifies the human-readable problem description.
+	Message string
+
+	// Position specifies the problem position in the cask content.
+	Position cask.Position
+}
+
+// String returns the string representation of the Problem in the
+// "line:column: severity: message (rule)" format.
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", p.Position.String(), p.Severity, p.Message, p.Rule)
+}
+
+// A Rule represents a single lint rule.
+type Rule struct {
+	// ID specifies the unique rule identifier.
+	ID string
+
+	// Severity specifies the severity of the problems reported by the rule.
+	Severity Severity
+
+	// Description specifies the human-readable rule description.
+	Description string
+
+	// Fixable specifies whether the rule problems can be fixed by the Fix.
+	Fixable bool
+
+	// check specifies the function that finds the rule problems.
+	check func(l *linter) []Problem
+}
+
+// A linter represents the state shared by all rules while linting a single
+// cask.
+type linter struct {
+	// cask specifies the linted cask.
+	cask *cask.Cask
+
+	// tree specifies the cask content SyntaxTree.
+	tree *cask.SyntaxTree
+
+	// rule specifies the currently running Rule.
+	rule *Rule
+}
+
+// Lint checks the provided parsed cask and returns the found problems sorted
+// by their position. By default, all Rules are used, unless specific rules are
+// passed. Returns an error if the cask content can't be tokenized.
+func Lint(c *cask.Cask, rules ...*Rule) ([]Problem, error) {
+	if len(rules) == 0 {
+		rules = Rules()
+	}
+
+	tree, err := cask.NewSyntaxTree(c.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{cask: c, tree: tree}
+
+	var problems []Problem
+	for _, r := range rules {
+		l.rule = r
+		problems = append(problems, r.check(l)...)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Position.Offset < problems[j].Position.Offset
+	})
+
+	return problems, nil
+}
+
+// problem returns a new Problem reported by the currently running Rule at the
+// specified content offset.
+func (l *linter) problem(offset int, format string, args ...interface{}) Problem {
+	return Problem{
+		Rule:     l.rule.ID,
+		Severity: l.rule.Severity,
+		Message:  fmt.Sprintf(format, args...),
+		Position: *cask.NewPosition(l.cask.Content, offset),
+	}
+}
@@ -0,0 +1,224 @@
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/victorpopkov/go-cask"
+)
+
+func getTestdata(filename string) string {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	return string(content)
+}
+
+func parseTestCask(t *testing.T, content string) *cask.Cask {
+	c := cask.NewCask(content)
+	assert.Nil(t, c.Parse())
+
+	return c
+}
+
+func problemStrings(problems []Problem) (result []string) {
+	for _, p := range problems {
+		result = append(result, p.String())
+	}
+
+	return result
+}
+
+func TestSeverityString(t *testing.T) {
+	assert.Equal(t, "warning", SeverityWarning.String())
+	assert.Equal(t, "error", SeverityError.String())
+}
+
+func TestProblemString(t *testing.T) {
+	// preparations
+	p := Problem{
+		Rule:     RuleInsecureURL,
+		Severity: SeverityWarning,
+		Message:  "url should use https:// instead of http://",
+		Position: cask.Position{Offset: 10, Line: 2, Column: 3},
+	}
+
+	// test
+	assert.Equal(t, "2:3: warning: url should use https:// instead of http:// (insecure-url)", p.String())
+}
+
+func TestRules(t *testing.T) {
+	// preparations
+	rules := Rules()
+
+	// test
+	var ids []string
+	for _, r := range rules {
+		assert.NotEmpty(t, r.Description)
+		ids = append(ids, r.ID)
+	}
+
+	assert.Equal(t, []string{
+		RuleStanzaOrder,
+		RuleRequiredStanza,
+		RuleInsecureURL,
+		RuleDuplicateName,
+		RuleTrailingWhitespace,
+		RuleQuoteStyle,
+		RuleDeprecatedArgument,
+	}, ids)
+}
+
+func TestLint(t *testing.T) {
+	// preparations
+	c := parseTestCask(t, getTestdata("problems.rb"))
+
+	// test
+	problems, err := Lint(c)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"3:3: warning: 'version' stanza should go before 'sha256' (stanza-order)",
+		"3:18: warning: trailing whitespace (trailing-whitespace)",
+		"5:7: warning: prefer single-quoted strings when you don't need string interpolation or special symbols (quote-style)",
+		"5:8: warning: url should use https:// instead of http:// (insecure-url)",
+		"7:9: warning: duplicated name 'Example' (duplicate-name)",
+		"10:7: warning: prefer single-quoted strings when you don't need string interpolation or special symbols (quote-style)",
+	}, problemStrings(problems))
+	assert.Equal(t, 97, problems[0].Position.Offset)
+}
+
+func TestLintRules(t *testing.T) {
+	// preparations
+	c := parseTestCask(t, getTestdata("problems.rb"))
+
+	var rule *Rule
+	for _, r := range Rules() {
+		if r.ID == RuleDuplicateName {
+			rule = r
+		}
+	}
+
+	// test
+	problems, err := Lint(c, rule)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"7:9: warning: duplicated name 'Example' (duplicate-name)",
+	}, problemStrings(problems))
+}
+
+func TestLintStanzaOrder(t *testing.T) {
+	// preparations
+	c := parseTestCask(t, `cask 'example' do
+  if MacOS.version <= :leopard
+    version '1.0.0'
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+  else
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+    version '2.0.0'
+
+    appcast 'https://example.com/appcast.xml'
+  end
+
+  url 'https://example.com/app.dmg'
+  homepage 'https://example.com/'
+  name 'Example'
+
+  auto_updates true
+
+  app 'Example.app'
+
+  uninstall quit: 'com.example'
+
+  postflight do
+    set_permissions "#{appdir}/Example.app", '0755'
+  end
+end
+`)
+
+	// test
+	problems, err := Lint(c)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"7:5: warning: 'version' stanza should go before 'sha256' (stanza-order)",
+		"14:3: warning: 'name' stanza should go before 'homepage' (stanza-order)",
+		"22:3: warning: 'postflight' stanza should go before 'uninstall' (stanza-order)",
+	}, problemStrings(problems))
+}
+
+func TestLintRequiredStanzas(t *testing.T) {
+	// preparations
+	c := parseTestCask(t, `# comment
+cask 'example' do
+  if MacOS.version <= :leopard
+    version '1.0.0'
+  else
+    version '2.0.0'
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+  end
+
+  url 'https://example.com/app.dmg'
+end
+`)
+
+	// test
+	problems, err := Lint(c)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"2:1: error: missing required stanza 'sha256' (required-stanza)",
+		"2:1: error: missing required stanza 'name' (required-stanza)",
+		"2:1: error: missing required stanza 'homepage' (required-stanza)",
+	}, problemStrings(problems))
+
+	// test (symbol values)
+	c = parseTestCask(t, `cask 'example' do
+  version :latest
+  sha256 :no_check
+
+  url 'https://example.com/app.dmg'
+  name 'Example'
+  homepage 'https://example.com/'
+end
+`)
+
+	problems, err = Lint(c)
+	assert.Nil(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestLintTestdata(t *testing.T) {
+	files, err := ioutil.ReadDir(filepath.Join("..", "testdata"))
+	assert.Nil(t, err)
+
+	for _, file := range files {
+		if file.IsDir() || file.Name() == "empty.rb" || file.Name() == "unknown-stanzas.rb" {
+			continue
+		}
+
+		// preparations
+		content, err := ioutil.ReadFile(filepath.Join("..", "testdata", file.Name()))
+		assert.Nil(t, err)
+		c := parseTestCask(t, string(content))
+
+		// test
+		problems, err := Lint(c)
+		assert.Nil(t, err)
+		assert.Empty(t, problemStrings(problems), file.Name())
+	}
+}
+
+func TestLintError(t *testing.T) {
+	// preparations
+	c := cask.NewCask("cask 'example' do\n  version '1.0.0\nend\n")
+
+	// test
+	problems, err := Lint(c)
+	assert.Nil(t, problems)
+	assert.EqualError(t, err, "Unterminated string at 28")
+}
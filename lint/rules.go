@@ -0,0 +1,259 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/victorpopkov/go-cask"
+)
+
+// Different rule IDs.
+const (
+	RuleStanzaOrder        = "stanza-order"
+	RuleRequiredStanza     = "required-stanza"
+	RuleInsecureURL        = "insecure-url"
+	RuleDuplicateName      = "duplicate-name"
+	RuleTrailingWhitespace = "trailing-whitespace"
+	RuleQuoteStyle         = "quote-style"
+	RuleDeprecatedArgument = "deprecated-argument"
+)
+
+// requiredStanzas specify the stanzas required in each cask variant.
+var requiredStanzas = []string{"version", "sha256", "url", "name", "homepage"}
+
+// urlStanzas specify the stanzas which values are URLs.
+var urlStanzas = []string{"url", "appcast", "homepage"}
+
+// Rules returns all available rules.
+func Rules() []*Rule {
+	return []*Rule{
+		{
+			ID:          RuleStanzaOrder,
+			Severity:    SeverityWarning,
+			Description: "stanzas should be in the canonical order",
+			Fixable:     true,
+			check:       checkStanzaOrder,
+		},
+		{
+			ID:          RuleRequiredStanza,
+			Severity:    SeverityError,
+			Description: "each cask should have the version, sha256, url, name and homepage stanzas",
+			check:       checkRequiredStanzas,
+		},
+		{
+			ID:          RuleInsecureURL,
+			Severity:    SeverityWarning,
+			Description: "url, appcast and homepage should use https:// when possible",
+			Fixable:     true,
+			check:       checkInsecureURLs,
+		},
+		{
+			ID:          RuleDuplicateName,
+			Severity:    SeverityWarning,
+			Description: "each name should be specified only once",
+			check:       checkDuplicateNames,
+		},
+		{
+			ID:          RuleTrailingWhitespace,
+			Severity:    SeverityWarning,
+			Description: "lines should not have trailing whitespaces",
+			check:       checkTrailingWhitespace,
+		},
+		{
+			ID:          RuleQuoteStyle,
+			Severity:    SeverityWarning,
+			Description: "strings without interpolations and special symbols should be single-quoted",
+			Fixable:     true,
+			check:       checkQuoteStyle,
+		},
+		{
+			ID:          RuleDeprecatedArgument,
+			Severity:    SeverityWarning,
+			Description: "the appcast checkpoint argument is deprecated",
+			Fixable:     true,
+			check:       checkDeprecatedArguments,
+		},
+	}
+}
+
+// checkStanzaOrder reports the stanzas that go after the ones which should
+// follow them in the canonical order. Only the sibling stanzas are compared
+// and the unknown stanzas are ignored.
+func checkStanzaOrder(l *linter) (problems []Problem) {
+	rank := make(map[string]int)
+	for _, group := range cask.StanzaOrder() {
+		for _, name := range group {
+			rank[name] = len(rank)
+		}
+	}
+
+	last := make(map[int]*cask.StanzaNode)
+	for _, s := range l.tree.Stanzas {
+		r, ok := rank[s.Name]
+		if !ok {
+			continue
+		}
+
+		previous := last[s.Block]
+		if previous != nil && r < rank[previous.Name] {
+			problems = append(problems, l.problem(
+				s.Tokens[0].Position,
+				"'%s' stanza should go before '%s'",
+				s.Name,
+				previous.Name,
+			))
+			continue
+		}
+
+		last[s.Block] = s
+	}
+
+	return problems
+}
+
+// checkRequiredStanzas reports the required stanzas missing in at least one
+// of the cask variants. The problems are reported at the cask block start.
+func checkRequiredStanzas(l *linter) (problems []Problem) {
+	offset := 0
+	for _, t := range l.tree.Tokens {
+		if t.Type == cask.IDENT && t.Literal == "cask" {
+			offset = t.Position
+			break
+		}
+	}
+
+	for _, name := range requiredStanzas {
+		if isStanzaMissing(l, name) {
+			problems = append(problems, l.problem(offset, "missing required stanza '%s'", name))
+		}
+	}
+
+	return problems
+}
+
+// isStanzaMissing checks whether the required stanza with the specified name
+// is missing in at least one of the cask variants.
+func isStanzaMissing(l *linter, name string) bool {
+	if len(l.tree.FindStanzas(name)) == 0 {
+		return true
+	}
+
+	for _, v := range l.cask.Variants {
+		switch {
+		case name == "version" && v.Version == nil,
+			name == "sha256" && v.SHA256 == nil,
+			name == "url" && v.URL == nil,
+			name == "name" && len(v.Names) == 0,
+			name == "homepage" && v.Homepage == nil:
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkDeprecatedArguments reports the deprecated "checkpoint:" arguments of
+// the appcast stanzas.
+func checkDeprecatedArguments(l *linter) (problems []Problem) {
+	for _, s := range l.tree.FindStanzas("appcast") {
+		if i := checkpointIndex(s); i > 0 {
+			problems = append(problems, l.problem(
+				s.Tokens[i].Position,
+				"appcast checkpoint argument is deprecated",
+			))
+		}
+	}
+
+	return problems
+}
+
+// checkpointIndex returns the StanzaNode.Tokens index of the "checkpoint"
+// argument name token. Returns -1 if there is no such argument.
+func checkpointIndex(s *cask.StanzaNode) int {
+	for i, t := range s.Tokens {
+		if i > 0 && t.Type == cask.IDENT && t.Literal == "checkpoint" {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// checkInsecureURLs reports the url, appcast and homepage stanzas using the
+// http:// URLs.
+func checkInsecureURLs(l *linter) (problems []Problem) {
+	for _, name := range urlStanzas {
+		for _, s := range l.tree.FindStanzas(name) {
+			t := s.ValueToken()
+			if t != nil && strings.HasPrefix(t.Literal, "http://") {
+				problems = append(problems, l.problem(t.Position, "%s should use https:// instead of http://", name))
+			}
+		}
+	}
+
+	return problems
+}
+
+// checkDuplicateNames reports the name stanzas with the value specified in
+// one of the previous sibling name stanzas.
+func checkDuplicateNames(l *linter) (problems []Problem) {
+	seen := make(map[int]map[string]bool)
+
+	for _, s := range l.tree.FindStanzas("name") {
+		t := s.ValueToken()
+		if t == nil {
+			continue
+		}
+
+		if seen[s.Block] == nil {
+			seen[s.Block] = make(map[string]bool)
+		}
+
+		if seen[s.Block][t.Literal] {
+			problems = append(problems, l.problem(t.Position, "duplicated name '%s'", t.Literal))
+			continue
+		}
+
+		seen[s.Block][t.Literal] = true
+	}
+
+	return problems
+}
+
+// checkTrailingWhitespace reports the lines ending with whitespaces.
+func checkTrailingWhitespace(l *linter) (problems []Problem) {
+	offset := 0
+
+	for _, line := range strings.SplitAfter(l.cask.Content, "\n") {
+		content := strings.TrimSuffix(line, "\n")
+		trimmed := strings.TrimRight(content, " \t")
+
+		if len(trimmed) != len(content) {
+			problems = append(problems, l.problem(offset+len(trimmed), "trailing whitespace"))
+		}
+
+		offset += len(line)
+	}
+
+	return problems
+}
+
+// checkQuoteStyle reports the double-quoted strings that don't need
+// interpolations or special symbols.
+func checkQuoteStyle(l *linter) (problems []Problem) {
+	for _, t := range l.tree.Tokens {
+		if t.Type != cask.STRING || !strings.HasSuffix(t.Leading, `"`) {
+			continue
+		}
+
+		if strings.Contains(t.Literal, "#{") || strings.ContainsAny(t.Literal, `'\`) {
+			continue
+		}
+
+		problems = append(problems, l.problem(
+			t.Position-1,
+			"prefer single-quoted strings when you don't need string interpolation or special symbols",
+		))
+	}
+
+	return problems
+}
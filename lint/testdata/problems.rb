@@ -0,0 +1,11 @@
+cask 'problems' do
+  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+  version '1.0.0' 
+
+  url "http://example.com/app_1.0.0.dmg"
+  name 'Example'
+  name 'Example'
+  homepage "https://example.com/#{version}/"
+
+  app "Example.app"
+end
@@ -0,0 +1,205 @@
+package cask
+
+import (
+	"strings"
+)
+
+// A LivecheckStrategy represents the strategy used by the livecheck stanza to
+// find the latest version.
+type LivecheckStrategy int
+
+// Different livecheck strategies. The StrategyDefault is used when the
+// strategy isn't specified, so it's chosen by Homebrew-Cask automatically.
+const (
+	StrategyDefault LivecheckStrategy = iota
+	StrategyApache
+	StrategyBitbucket
+	StrategyElectronBuilder
+	StrategyExtractPlist
+	StrategyGit
+	StrategyGithubLatest
+	StrategyGithubReleases
+	StrategyGnome
+	StrategyGnu
+	StrategyHeaderMatch
+	StrategyJSON
+	StrategyLaunchpad
+	StrategyNpm
+	StrategyPageMatch
+	StrategyPypi
+	StrategySourceforge
+	StrategySparkle
+	StrategyXML
+	StrategyXorg
+	StrategyYAML
+)
+
+var livecheckStrategySymbols = [...]string{
+	"",
+	"apache",
+	"bitbucket",
+	"electron_builder",
+	"extract_plist",
+	"git",
+	"github_latest",
+	"github_releases",
+	"gnome",
+	"gnu",
+	"header_match",
+	"json",
+	"launchpad",
+	"npm",
+	"page_match",
+	"pypi",
+	"sourceforge",
+	"sparkle",
+	"xml",
+	"xorg",
+	"yaml",
+}
+
+// A Livecheck represents a livecheck cask stanza.
+type Livecheck struct {
+	BaseStanza
+
+	// URL specifies the checked URL. It's empty when the URL references
+	// another stanza.
+	URL string
+
+	// URLReference specifies the name of the stanza which URL is checked:
+	// "url" or "homepage" for the "url :url" and "url :homepage" respectively.
+	URLReference string
+
+	// Strategy specifies the livecheck strategy.
+	Strategy LivecheckStrategy
+
+	// UnknownStrategy specifies the raw symbol name of the strategy unknown to
+	// this package, in which case the Strategy is the StrategyDefault.
+	UnknownStrategy string
+
+	// Regex specifies the regular expression source without the delimiters.
+	Regex string
+
+	// RegexFlags specifies the regular expression flags. For example, "i".
+	RegexFlags string
+
+	// HasStrategyBlock specifies if the strategy has a custom "do |page| ...
+	// end" block.
+	HasStrategyBlock bool
+
+	// StrategyBlock specifies the raw source of the custom strategy block
+	// starting from "do" and ending with "end".
+	StrategyBlock string
+}
+
+// NewLivecheck creates a new Livecheck instance and returns its pointer.
+// Requires Livecheck.URL to be passed as argument.
+func NewLivecheck(url string) *Livecheck {
+	return &Livecheck{
+		URL: url,
+	}
+}
+
+// livecheckStrategyFromSymbol returns the LivecheckStrategy matching the
+// provided Ruby symbol name. The second value reports whether the strategy was
+// found.
+func livecheckStrategyFromSymbol(symbol string) (LivecheckStrategy, bool) {
+	for i, s := range livecheckStrategySymbols {
+		if i > 0 && s == symbol {
+			return LivecheckStrategy(i), true
+		}
+	}
+
+	return StrategyDefault, false
+}
+
+// Symbol returns the LivecheckStrategy Ruby symbol name. Returns an empty
+// string for the StrategyDefault.
+func (s LivecheckStrategy) Symbol() string {
+	return livecheckStrategySymbols[s]
+}
+
+// String returns the string representation of the LivecheckStrategy, which is
+// its Ruby symbol name or "default" for the StrategyDefault.
+func (s LivecheckStrategy) String() string {
+	if s == StrategyDefault {
+		return "default"
+	}
+
+	return s.Symbol()
+}
+
+// strategySymbol returns the Ruby symbol name of the Livecheck.Strategy or the
+// Livecheck.UnknownStrategy. Returns an empty string for the default strategy.
+func (l Livecheck) strategySymbol() string {
+	if l.Strategy == StrategyDefault {
+		return l.UnknownStrategy
+	}
+
+	return l.Strategy.Symbol()
+}
+
+// String returns a string representation of the Livecheck struct which is the
+// Livecheck.URL or the referenced stanza symbol.
+func (l Livecheck) String() string {
+	if l.URLReference != "" {
+		return ":" + l.URLReference
+	}
+
+	return l.URL
+}
+
+// Arguments returns the Livecheck Ruby stanza arguments, which is the whole
+// "do ... end" block. The custom strategy block is reindented to match the
+// block.
+func (l Livecheck) Arguments() string {
+	lines := []string{"do"}
+
+	switch {
+	case l.URLReference != "":
+		lines = append(lines, "  url :"+l.URLReference)
+	case l.URL != "":
+		lines = append(lines, "  url "+rubyString(l.URL))
+	}
+
+	if symbol := l.strategySymbol(); symbol != "" {
+		strategy := "  strategy :" + symbol
+		if l.HasStrategyBlock {
+			strategy += " " + reindentBlock(l.StrategyBlock, "  ")
+		}
+		lines = append(lines, strategy)
+	}
+
+	if l.Regex != "" {
+		regex := "/" + l.Regex + "/"
+		if strings.Contains(l.Regex, "/") {
+			regex = "%r{" + l.Regex + "}"
+		}
+		lines = append(lines, "  regex("+regex+l.RegexFlags+")")
+	}
+
+	lines = append(lines, "end")
+
+	return strings.Join(lines, "\n")
+}
+
+// reindentBlock returns the provided raw block source with the lines following
+// the first one reindented. The indentation of the last line, which closes the
+// block, is replaced by the provided one and the rest of the lines keep their
+// relative indentation.
+func reindentBlock(block string, indent string) string {
+	lines := strings.Split(block, "\n")
+	last := lines[len(lines)-1]
+	current := last[:len(last)-len(strings.TrimLeft(last, " \t"))]
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = ""
+			continue
+		}
+
+		lines[i] = indent + strings.TrimPrefix(lines[i], current)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,77 @@
+package cask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLivecheckStrategy(t *testing.T) {
+	// test
+	assert.Equal(t, "sparkle", StrategySparkle.String())
+	assert.Equal(t, "github_latest", StrategyGithubLatest.Symbol())
+	assert.Equal(t, "default", StrategyDefault.String())
+	assert.Empty(t, StrategyDefault.Symbol())
+
+	strategy, ok := livecheckStrategyFromSymbol("page_match")
+	assert.True(t, ok)
+	assert.Equal(t, StrategyPageMatch, strategy)
+
+	strategy, ok = livecheckStrategyFromSymbol("")
+	assert.False(t, ok)
+	assert.Equal(t, StrategyDefault, strategy)
+}
+
+func TestNewLivecheck(t *testing.T) {
+	// preparations
+	l := NewLivecheck("https://example.com/appcast.xml")
+
+	// test
+	assert.IsType(t, Livecheck{}, *l)
+	assert.Equal(t, "https://example.com/appcast.xml", l.URL)
+	assert.Equal(t, "https://example.com/appcast.xml", l.String())
+
+	l.URLReference = "homepage"
+	assert.Equal(t, ":homepage", l.String())
+}
+
+func TestLivecheckArguments(t *testing.T) {
+	// preparations
+	l := NewLivecheck("https://example.com/#{version.major}/appcast.xml")
+	l.Strategy = StrategyPageMatch
+	l.Regex = `/app-(\d+)\.dmg`
+	l.RegexFlags = "i"
+	l.HasStrategyBlock = true
+	l.StrategyBlock = "do |page|\n          page[/app-(\\d+)/, 1]\n\n        end"
+
+	// test
+	assert.Equal(t, `do
+  url "https://example.com/#{version.major}/appcast.xml"
+  strategy :page_match do |page|
+    page[/app-(\d+)/, 1]
+
+  end
+  regex(%r{/app-(\d+)\.dmg}i)
+end`, l.Arguments())
+
+	assert.Equal(t, "do\n  regex(/app-(\\d+)\\.dmg/)\nend", Livecheck{Regex: `app-(\d+)\.dmg`}.Arguments())
+	assert.Equal(t, "do\n  strategy :unknown\nend", Livecheck{UnknownStrategy: "unknown"}.Arguments())
+}
+
+func TestVariantGetLivecheck(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("livecheck.rb")))
+	assert.Nil(t, c.Parse())
+
+	v := c.Variants[0]
+	v.Livecheck.URL = "https://example.com/#{version}/appcast.xml"
+
+	// test
+	assert.Equal(t, "https://example.com/1.0.0/appcast.xml", v.GetLivecheck().URL)
+	assert.Equal(t, Livecheck{}, NewVariant().GetLivecheck())
+	assert.Equal(t, []FieldChange{
+		{"version", "1.0.0", "2.0.0"},
+		{"url", "https://example.com/app_1.0.0.dmg", "https://example.com/app_2.0.0.dmg"},
+		{"livecheck", "https://example.com/1.0.0/appcast.xml", "https://example.com/2.0.0/appcast.xml"},
+	}, v.VersionChanges("2.0.0"))
+}
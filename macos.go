@@ -45,6 +45,32 @@ var macOSVersion = [...]string{
 	"10.4",
 }
 
+var macOSSymbols = [...]string{
+	"high_sierra",
+	"sierra",
+	"el_capitan",
+	"yosemite",
+	"mavericks",
+	"mountain_lion",
+	"lion",
+	"snow_leopard",
+	"leopard",
+	"tiger",
+}
+
+// macOSFromSymbol returns the MacOS release matching the provided Ruby symbol
+// name used in the "MacOS.version" conditions. The second value reports
+// whether the release was found.
+func macOSFromSymbol(symbol string) (MacOS, bool) {
+	for i, s := range macOSSymbols {
+		if s == symbol {
+			return MacOS(i), true
+		}
+	}
+
+	return 0, false
+}
+
 // Name returns the MacOS release name.
 func (m MacOS) Name() string {
 	return macOSNames[m]
@@ -55,6 +81,29 @@ func (m MacOS) Version() string {
 	return macOSVersion[m]
 }
 
+// Symbol returns the MacOS release Ruby symbol name used in the "MacOS.version"
+// conditions.
+func (m MacOS) Symbol() string {
+	return macOSSymbols[m]
+}
+
+// MarshalText returns the MacOS.Symbol, so the MacOS is encoded as its Ruby
+// symbol name in the JSON, YAML and TOML documents.
+func (m MacOS) MarshalText() ([]byte, error) {
+	return []byte(m.Symbol()), nil
+}
+
+// UnmarshalText decodes the MacOS release from its Ruby symbol name.
+func (m *MacOS) UnmarshalText(text []byte) error {
+	mac, ok := macOSFromSymbol(string(text))
+	if !ok {
+		return fmt.Errorf("unknown macOS release '%s'", text)
+	}
+	*m = mac
+
+	return nil
+}
+
 // String returns the string representation of the MacOS release.
 func (m MacOS) String() string {
 	return fmt.Sprintf("%s (%s)", macOSNames[m], macOSVersion[m])
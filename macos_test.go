@@ -44,3 +44,32 @@ func TestMacOSString(t *testing.T) {
 	assert.Equal(t, "Mac OS X Leopard (10.5)", MacOSLeopard.String())
 	assert.Equal(t, "Mac OS X Tiger (10.4)", MacOSTiger.String())
 }
+
+func TestMacOSSymbol(t *testing.T) {
+	assert.Equal(t, "high_sierra", MacOSHighSierra.Symbol())
+	assert.Equal(t, "sierra", MacOSSierra.Symbol())
+	assert.Equal(t, "el_capitan", MacOSElCapitan.Symbol())
+	assert.Equal(t, "yosemite", MacOSYosemite.Symbol())
+	assert.Equal(t, "mavericks", MacOSMavericks.Symbol())
+	assert.Equal(t, "mountain_lion", MacOSMountainLion.Symbol())
+	assert.Equal(t, "lion", MacOSLion.Symbol())
+	assert.Equal(t, "snow_leopard", MacOSSnowLeopard.Symbol())
+	assert.Equal(t, "leopard", MacOSLeopard.Symbol())
+	assert.Equal(t, "tiger", MacOSTiger.Symbol())
+}
+
+func TestMacOSMarshalText(t *testing.T) {
+	text, err := MacOSSierra.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "sierra", string(text))
+}
+
+func TestMacOSUnmarshalText(t *testing.T) {
+	var mac MacOS
+
+	assert.Nil(t, mac.UnmarshalText([]byte("el_capitan")))
+	assert.Equal(t, MacOSElCapitan, mac)
+
+	assert.EqualError(t, mac.UnmarshalText([]byte("unknown")), "unknown macOS release 'unknown'")
+	assert.Equal(t, MacOSElCapitan, mac)
+}
@@ -0,0 +1,148 @@
+package cask
+
+import "time"
+
+// An Option represents a function that configures the ParseOptions. Options
+// can be passed to both NewCask and NewParser.
+type Option func(*ParseOptions)
+
+// ParseOptions represents all options that configure the Parser.
+type ParseOptions struct {
+	// Mode specifies the Parser mode. By default, it's ModeLenient.
+	Mode Mode
+
+	// MacOSReleases specifies the target macOS releases. The newest release is
+	// used as the default for variants without conditions and as the upper
+	// bound for "MacOS.version" conditions. The oldest one is used as the lower
+	// bound. By default, all known macOS releases are targeted.
+	MacOSReleases []MacOS
+
+	// EagerInterpolation specifies whether the string interpolations should be
+	// resolved right after parsing, so the stanza values already hold the
+	// interpolated strings. By default, it's false.
+	EagerInterpolation bool
+
+	// InterpolationVariables specify the interpolation variables that override
+	// the DefaultInterpolationVariables. For example, "appdir" or "language".
+	InterpolationVariables map[string]string
+
+	// MaxInputSize specifies the maximum cask content size in bytes. By
+	// default, it's 0 which means no limit.
+	MaxInputSize int
+
+	// StanzaRegistry specifies the StanzaRegistry used to recognise and parse
+	// the stanzas. By default, it's nil which means the DefaultStanzaRegistry.
+	StanzaRegistry *StanzaRegistry
+
+	// ReferenceTime specifies the time as of which the cask deprecation and
+	// disabling are evaluated when encoding JSON. By default, it's the zero
+	// time which means that both are in effect regardless of their dates.
+	ReferenceTime time.Time
+}
+
+// NewParseOptions creates a new ParseOptions instance with the specified
+// options applied over the defaults and returns its pointer.
+func NewParseOptions(opts ...Option) *ParseOptions {
+	o := &ParseOptions{
+		Mode: ModeLenient,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithMode returns an Option that sets the ParseOptions.Mode.
+func WithMode(mode Mode) Option {
+	return func(o *ParseOptions) {
+		o.Mode = mode
+	}
+}
+
+// WithStrictMode returns an Option that sets the ParseOptions.Mode to
+// ModeStrict. It's a WithMode convenience function.
+func WithStrictMode() Option {
+	return WithMode(ModeStrict)
+}
+
+// WithMacOSReleases returns an Option that sets the ParseOptions.MacOSReleases.
+func WithMacOSReleases(releases ...MacOS) Option {
+	return func(o *ParseOptions) {
+		o.MacOSReleases = releases
+	}
+}
+
+// WithEagerInterpolation returns an Option that sets the
+// ParseOptions.EagerInterpolation.
+func WithEagerInterpolation(eager bool) Option {
+	return func(o *ParseOptions) {
+		o.EagerInterpolation = eager
+	}
+}
+
+// WithInterpolationVariables returns an Option that sets the
+// ParseOptions.InterpolationVariables.
+func WithInterpolationVariables(variables map[string]string) Option {
+	return func(o *ParseOptions) {
+		o.InterpolationVariables = variables
+	}
+}
+
+// WithMaxInputSize returns an Option that sets the ParseOptions.MaxInputSize.
+func WithMaxInputSize(size int) Option {
+	return func(o *ParseOptions) {
+		o.MaxInputSize = size
+	}
+}
+
+// WithStanzaRegistry returns an Option that sets the
+// ParseOptions.StanzaRegistry.
+func WithStanzaRegistry(registry *StanzaRegistry) Option {
+	return func(o *ParseOptions) {
+		o.StanzaRegistry = registry
+	}
+}
+
+// WithReferenceTime returns an Option that sets the
+// ParseOptions.ReferenceTime.
+func WithReferenceTime(t time.Time) Option {
+	return func(o *ParseOptions) {
+		o.ReferenceTime = t
+	}
+}
+
+// LatestMacOS returns the newest macOS release from the
+// ParseOptions.MacOSReleases. By default, it's MacOSHighSierra.
+func (o ParseOptions) LatestMacOS() MacOS {
+	if len(o.MacOSReleases) == 0 {
+		return MacOSHighSierra
+	}
+
+	latest := o.MacOSReleases[0]
+	for _, m := range o.MacOSReleases {
+		if m < latest {
+			latest = m
+		}
+	}
+
+	return latest
+}
+
+// OldestMacOS returns the oldest macOS release from the
+// ParseOptions.MacOSReleases. By default, it's MacOSTiger.
+func (o ParseOptions) OldestMacOS() MacOS {
+	if len(o.MacOSReleases) == 0 {
+		return MacOSTiger
+	}
+
+	oldest := o.MacOSReleases[0]
+	for _, m := range o.MacOSReleases {
+		if m > oldest {
+			oldest = m
+		}
+	}
+
+	return oldest
+}
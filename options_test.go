@@ -0,0 +1,89 @@
+package cask
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewParseOptions(t *testing.T) {
+	// test (defaults)
+	o := NewParseOptions()
+	assert.IsType(t, ParseOptions{}, *o)
+	assert.Equal(t, ModeLenient, o.Mode)
+	assert.Len(t, o.MacOSReleases, 0)
+	assert.False(t, o.EagerInterpolation)
+	assert.Equal(t, 0, o.MaxInputSize)
+	assert.True(t, o.ReferenceTime.IsZero())
+
+	// test (options)
+	o = NewParseOptions(
+		WithStrictMode(),
+		WithMacOSReleases(MacOSSierra, MacOSElCapitan),
+		WithEagerInterpolation(true),
+		WithMaxInputSize(1024),
+		WithReferenceTime(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)),
+	)
+	assert.Equal(t, ModeStrict, o.Mode)
+	assert.Equal(t, []MacOS{MacOSSierra, MacOSElCapitan}, o.MacOSReleases)
+	assert.True(t, o.EagerInterpolation)
+	assert.Equal(t, 1024, o.MaxInputSize)
+	assert.Equal(t, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), o.ReferenceTime)
+}
+
+func TestWithMode(t *testing.T) {
+	assert.Equal(t, ModeStrict, NewParseOptions(WithMode(ModeStrict)).Mode)
+	assert.Equal(t, ModeLenient, NewParseOptions(WithMode(ModeLenient)).Mode)
+}
+
+func TestParseOptionsLatestMacOS(t *testing.T) {
+	assert.Equal(t, MacOSHighSierra, NewParseOptions().LatestMacOS())
+	assert.Equal(t, MacOSSierra, NewParseOptions(
+		WithMacOSReleases(MacOSYosemite, MacOSSierra, MacOSElCapitan),
+	).LatestMacOS())
+}
+
+func TestParseOptionsOldestMacOS(t *testing.T) {
+	assert.Equal(t, MacOSTiger, NewParseOptions().OldestMacOS())
+	assert.Equal(t, MacOSYosemite, NewParseOptions(
+		WithMacOSReleases(MacOSSierra, MacOSYosemite, MacOSElCapitan),
+	).OldestMacOS())
+}
+
+func TestParseWithOptions(t *testing.T) {
+	content := string(getTestdata("example-two.rb"))
+
+	// test (strict mode)
+	c := NewCask(content, WithStrictMode())
+	assert.Error(t, c.Parse())
+
+	// test (macOS releases)
+	c = NewCask(content, WithMacOSReleases(MacOSSierra, MacOSElCapitan, MacOSYosemite))
+	assert.Nil(t, c.Parse())
+	assert.Len(t, c.Variants, 2)
+	assert.Equal(t, MacOSYosemite, c.Variants[0].MinimumSupportedMacOS)
+	assert.Equal(t, MacOSElCapitan, c.Variants[0].MaximumSupportedMacOS)
+	assert.Equal(t, MacOSSierra, c.Variants[1].MinimumSupportedMacOS)
+	assert.Equal(t, MacOSSierra, c.Variants[1].MaximumSupportedMacOS)
+
+	// test (eager interpolation)
+	c = NewCask(content, WithEagerInterpolation(true))
+	assert.Nil(t, c.Parse())
+	assert.Equal(t, "https://example.com/app_1.5.0.pkg", c.Variants[0].URL.Value)
+	assert.Equal(t, "https://example.com/sparkle/1/el_capitan.xml", c.Variants[0].Appcast.URL)
+	assert.Equal(t, "app_1.5.0.pkg", c.Variants[0].Artifacts[0].Value)
+	assert.Equal(t, "https://example.com/app_2.0.0.pkg", c.Variants[1].URL.Value)
+	assert.Equal(t, "app_2.0.0.pkg", c.Variants[1].Artifacts[0].Value)
+	assert.True(t, c.Variants[1].Artifacts[0].AllowUntrusted)
+
+	// test (maximum input size)
+	c = NewCask(content, WithMaxInputSize(10))
+	err := c.Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximum input size (10 bytes)")
+	assert.Len(t, c.Variants, 0)
+
+	c = NewCask(content, WithMaxInputSize(len(content)))
+	assert.Nil(t, c.Parse())
+}
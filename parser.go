@@ -2,10 +2,26 @@ package cask
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+// A Mode represents the Parser mode which specifies how the unknown stanzas
+// and the stanzas that can't be parsed should be reported.
+type Mode int
+
+// Different Parser modes.
+const (
+	// ModeLenient reports unknown stanzas and stanza parsing errors as
+	// warnings. This is the default.
+	ModeLenient Mode = iota
+
+	// ModeStrict reports unknown stanzas and stanza parsing errors as errors.
+	ModeStrict
+)
+
 // A Parser represents the parser that uses the emitted token provided by Lexer.
 type Parser struct {
 	// cask specifies the parsed Cask.
@@ -14,6 +30,9 @@ type Parser struct {
 	// lexer specifies Lexer pointer.
 	lexer *Lexer
 
+	// options specify the ParseOptions used during parsing.
+	options ParseOptions
+
 	// currentToken specifies the current emitted Lexer token.
 	currentToken Token
 
@@ -23,6 +42,12 @@ type Parser struct {
 	// errors specify an array of errors.
 	errors []error
 
+	// warnings specify an array of warnings.
+	warnings []error
+
+	// statements specify the statementTracker used to find the stanzas.
+	statements statementTracker
+
 	// currentCaskVariant specifies the temporary cask Variant that currently
 	// being parsed.
 	currentCaskVariant *Variant
@@ -34,14 +59,25 @@ type Parser struct {
 	// insideIfElse specifies if the parser is currently inside of the if
 	// statement.
 	insideIfElse bool
+
+	// ifArch specifies the Arch from the last if condition, so the else block
+	// gets the opposite one.
+	ifArch Arch
+
+	// variantStanzas specifies the number of stanzas added to the cask variants,
+	// so the conditions of the blocks without them are ignored.
+	variantStanzas int
 }
 
 // NewParser creates a new Parser instance and returns its pointer. Requires a
-// Lexer and a Cask to be specified as arguments.
-func NewParser(lexer *Lexer) *Parser {
+// Lexer to be specified as an argument. Optionally, the Option functions can be
+// passed to configure the Parser.
+func NewParser(lexer *Lexer, opts ...Option) *Parser {
 	p := &Parser{
-		lexer:  lexer,
-		errors: []error{},
+		lexer:    lexer,
+		options:  *NewParseOptions(opts...),
+		errors:   []error{},
+		warnings: []error{},
 	}
 
 	// read two tokens, so both currentToken and peekToken are set
@@ -55,18 +91,33 @@ func NewParser(lexer *Lexer) *Parser {
 func (p *Parser) ParseCask(cask *Cask) error {
 	p.cask = cask
 
+	max := p.options.MaxInputSize
+	if max > 0 && len(p.lexer.input) > max {
+		return fmt.Errorf(
+			"cask content size (%d bytes) exceeds the maximum input size (%d bytes)",
+			len(p.lexer.input),
+			max,
+		)
+	}
+
 	for !p.currentTokenIs(EOF) {
 		p.parseStatement()
-
-		err := p.nextToken()
-		if err != nil {
-			break
-		}
+		p.nextToken()
 	}
 
-	if p.currentCaskVariant != nil {
+	if p.currentCaskVariant != nil && !p.currentCaskVariant.isEmpty() {
 		p.cask.AddVariant(p.currentCaskVariant)
-		p.currentCaskVariant = nil
+	}
+	p.currentCaskVariant = nil
+
+	if p.cask.Token == "" {
+		p.errors = append(p.errors, errors.New("cask block not found"))
+		return NewErrors("Parsing errors", p.errors...)
+	}
+
+	if len(p.cask.Variants) == 0 {
+		p.errors = append(p.errors, fmt.Errorf("cask '%s' has no supported stanzas", p.cask.Token))
+		return NewErrors("Parsing errors", p.errors...)
 	}
 
 	first := p.cask.Variants[0]
@@ -94,6 +145,11 @@ func (p *Parser) ParseCask(cask *Cask) error {
 			v.Appcast = last.Appcast
 		}
 
+		// livecheck
+		if v.Livecheck == nil && last.Livecheck != nil && last.Livecheck.IsGlobal {
+			v.Livecheck = last.Livecheck
+		}
+
 		// name
 		if len(v.Names) == 0 && len(last.Names) != 0 {
 			for _, n := range last.Names {
@@ -114,8 +170,33 @@ func (p *Parser) ParseCask(cask *Cask) error {
 				v.AddArtifact(a)
 			}
 		}
+
+		// custom stanzas
+		for name, stanzas := range last.Stanzas {
+			if len(v.GetStanzas(name)) > 0 {
+				continue
+			}
+
+			for _, s := range stanzas {
+				if g, ok := s.(globalStanza); ok && g.isGlobal() {
+					v.AddStanza(name, s)
+				}
+			}
+		}
+	}
+
+	for _, v := range p.cask.Variants {
+		v.variables = p.interpolationVariables()
 	}
 
+	if p.options.EagerInterpolation {
+		for _, v := range p.cask.Variants {
+			v.resolveInterpolations()
+		}
+	}
+
+	p.cask.Warnings = p.warnings
+
 	if len(p.errors) != 0 {
 		return NewErrors("Parsing errors", p.errors...)
 	}
@@ -123,6 +204,12 @@ func (p *Parser) ParseCask(cask *Cask) error {
 	return nil
 }
 
+// SetMode sets the Parser mode. It's the same as passing the WithMode option
+// to the NewParser.
+func (p *Parser) SetMode(mode Mode) {
+	p.options.Mode = mode
+}
+
 // parseStatement parses a single statement.
 func (p *Parser) parseStatement() {
 	switch p.currentToken.Type {
@@ -141,111 +228,33 @@ func (p *Parser) parseStatement() {
 // parseExpressionStatement parses a single expression statement.
 func (p *Parser) parseExpressionStatement() {
 	if p.currentCaskVariant == nil {
-		p.currentCaskVariant = NewVariant()
+		p.currentCaskVariant = p.newVariant()
 	}
 
 	switch p.currentToken.Type {
 	case IDENT:
+		arch, isArchBlock := archFromBlock(p.currentToken.Literal)
+		isArchBlock = isArchBlock && p.peekTokenIs(DO)
+
+		if p.statements.isCaskStatementStart() && !isArchBlock && !p.stanzaRegistry().Has(p.currentToken.Literal) {
+			p.unknownStanza()
+		}
+
+		// the cask token can match one of the stanza names
 		if p.currentToken.Literal == "cask" {
 			if p.peekTokenIs(STRING) {
 				p.accept(STRING)
 				p.cask.Token = p.currentToken.Literal
 			}
-		}
-
-		if p.peekTokenIs(STRING) {
-			switch p.currentToken.Literal {
-			case "sha256":
-				if p.currentCaskVariant.SHA256 != nil {
-					p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.SHA256.Value)
-				}
-
-				s := NewSHA256(p.peekToken.Literal)
-				if !p.insideIfElse {
-					s.IsGlobal = true
-				}
-				p.currentCaskVariant.SHA256 = s
-			case "url":
-				if p.currentCaskVariant.URL != nil {
-					p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.URL.Value)
-				}
-
-				u := NewURL(p.peekToken.Literal)
-				if !p.insideIfElse {
-					u.IsGlobal = true
-				}
-				p.currentCaskVariant.URL = u
-			case "appcast":
-				if p.currentCaskVariant.Appcast != nil {
-					p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Appcast.URL)
-				}
-
-				a, err := p.parseAppcast()
-				if err == nil {
-					if !p.insideIfElse {
-						a.IsGlobal = true
-					}
-					p.currentCaskVariant.Appcast = a
-				}
-			case "name":
-				p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Names)
-
-				n := NewName(p.peekToken.Literal)
-				if !p.insideIfElse {
-					n.IsGlobal = true
-				}
-				p.currentCaskVariant.AddName(n)
-			case "homepage":
-				if p.currentCaskVariant.Homepage != nil {
-					p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Homepage.Value)
-				}
-
-				h := NewHomepage(p.peekToken.Literal)
-				if !p.insideIfElse {
-					h.IsGlobal = true
-				}
-				p.currentCaskVariant.Homepage = h
-			case "version":
-				if p.currentCaskVariant.Version != nil {
-					p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Version.Value)
-				}
-
-				v, err := p.parseVersion()
-				if err == nil {
-					if !p.insideIfElse {
-						v.IsGlobal = true
-					}
-					p.currentCaskVariant.Version = v
-				}
-			}
-
-			// artifacts
-			if p.currentTokenLiteralOneOf("app", "pkg", "binary") {
-				if p.currentIfVariant != nil {
-					p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Artifacts)
-				}
-
-				a, err := p.ParseArtifact()
-				if err == nil && a != nil {
-					p.currentCaskVariant.AddArtifact(a)
-				}
-			}
-		}
-
-		if p.peekTokenIs(SYMBOL) {
-			switch p.currentToken.Literal {
-			case "version":
-				if p.currentCaskVariant.Version != nil {
-					p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Version.Value)
-				}
-
-				v, err := p.parseVersion()
-				if err == nil {
-					if !p.insideIfElse {
-						v.IsGlobal = true
-					}
-					p.currentCaskVariant.Version = v
-				}
+		} else if isArchBlock {
+			p.parseArchBlock(arch)
+		} else if parse, ok := p.stanzaRegistry().Lookup(p.currentToken.Literal); ok {
+			name, position := p.currentToken.Literal, p.currentToken.Position
+			stanza, err := parse(p)
+			if err != nil {
+				p.stanzaError(name, position, err)
+			} else if stanza != nil {
+				p.addStanza(name, stanza)
 			}
 		}
 	case IF:
@@ -253,8 +262,15 @@ func (p *Parser) parseExpressionStatement() {
 	case ELSEIF:
 		p.parseIfExpression()
 	case ELSE:
+		stanzas := p.variantStanzas
+
 		p.insideIfElse = true
 		p.parseBlockStatement()
+
+		if p.ifArch != ArchAll && p.variantStanzas != stanzas {
+			p.currentCaskVariant.Arch = p.ifArch.Opposite()
+			p.ifArch = ArchAll
+		}
 	}
 
 	if p.peekTokenOneOf(SEMICOLON, NEWLINE, COMMA) {
@@ -262,6 +278,66 @@ func (p *Parser) parseExpressionStatement() {
 	}
 }
 
+// addStanza adds the parsed stanza to the Parser.currentCaskVariant. If the
+// current variant already has the same stanza, it's merged into the
+// Cask.Variants and a new one is started. Unless the Parser is inside the if
+// statement, the stanza is marked as global. The LifecycleEvent stanzas belong
+// to the whole cask and are added to the Cask.Lifecycle.
+func (p *Parser) addStanza(name string, stanza Stanza) {
+	if g, ok := stanza.(globalStanza); ok && !p.insideIfElse {
+		g.setGlobal(true)
+	}
+
+	if _, ok := stanza.(*LifecycleEvent); !ok {
+		p.variantStanzas++
+	}
+
+	switch s := stanza.(type) {
+	case *Version:
+		if p.currentCaskVariant.Version != nil {
+			p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Version.Value)
+		}
+		p.currentCaskVariant.Version = s
+	case *SHA256:
+		if p.currentCaskVariant.SHA256 != nil {
+			p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.SHA256.Value)
+		}
+		p.currentCaskVariant.SHA256 = s
+	case *URL:
+		if p.currentCaskVariant.URL != nil {
+			p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.URL.Value)
+		}
+		p.currentCaskVariant.URL = s
+	case *Appcast:
+		if p.currentCaskVariant.Appcast != nil {
+			p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Appcast.URL)
+		}
+		p.currentCaskVariant.Appcast = s
+	case *Livecheck:
+		if p.currentCaskVariant.Livecheck != nil {
+			p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Livecheck.String())
+		}
+		p.currentCaskVariant.Livecheck = s
+	case *Name:
+		p.currentCaskVariant.AddName(s)
+	case *Homepage:
+		if p.currentCaskVariant.Homepage != nil {
+			p.mergeCurrentCaskVariantIfNotEmpty(p.currentCaskVariant.Homepage.Value)
+		}
+		p.currentCaskVariant.Homepage = s
+	case *Artifact:
+		p.currentCaskVariant.AddArtifact(s)
+	case *LifecycleEvent:
+		if name == "disable!" {
+			p.cask.Lifecycle.Disabled = s
+		} else {
+			p.cask.Lifecycle.Deprecated = s
+		}
+	default:
+		p.currentCaskVariant.AddStanza(name, s)
+	}
+}
+
 func (p *Parser) parseIfExpression() {
 	p.nextToken()
 
@@ -289,11 +365,17 @@ func (p *Parser) parseIfExpression() {
 		return
 	}
 
+	stanzas := p.variantStanzas
 	p.parseBlockStatement(ELSE, ELSEIF)
 
 	if p.currentIfVariant != nil {
-		p.currentCaskVariant.MinimumSupportedMacOS = p.currentIfVariant.MinimumSupportedMacOS
-		p.currentCaskVariant.MaximumSupportedMacOS = p.currentIfVariant.MaximumSupportedMacOS
+		// the condition applies only to the variant with the stanzas from the block
+		if p.variantStanzas != stanzas {
+			p.currentCaskVariant.MinimumSupportedMacOS = p.currentIfVariant.MinimumSupportedMacOS
+			p.currentCaskVariant.MaximumSupportedMacOS = p.currentIfVariant.MaximumSupportedMacOS
+			p.currentCaskVariant.Arch = p.currentIfVariant.Arch
+		}
+		p.ifArch = p.currentIfVariant.Arch
 		p.currentIfVariant = nil
 	}
 
@@ -304,9 +386,14 @@ func (p *Parser) parseIfExpression() {
 
 // parseIfCondition parses the if condition if it's supported.
 func (p *Parser) parseIfCondition() {
-	p.currentIfVariant = NewVariant()
+	p.currentIfVariant = p.newVariant()
 	p.insideIfElse = true
 
+	if arch, ok := p.parseConditionArch(); ok {
+		p.currentIfVariant.Arch = arch
+		return
+	}
+
 	min, max, err := p.ParseConditionMacOS()
 	if err == nil {
 		p.currentIfVariant.MinimumSupportedMacOS = min
@@ -315,6 +402,43 @@ func (p *Parser) parseIfCondition() {
 	}
 }
 
+// parseConditionArch parses the "Hardware::CPU.intel?" and
+// "Hardware::CPU.arm?" conditions. The second value reports whether the
+// condition was found.
+func (p *Parser) parseConditionArch() (Arch, bool) {
+	if !p.currentTokenIs(CONST) || p.currentToken.Literal != "Hardware" {
+		return ArchAll, false
+	}
+
+	if !p.accept(SCOPE) || !p.peekTokenIs(CONST) || p.peekToken.Literal != "CPU" {
+		return ArchAll, false
+	}
+	p.accept(CONST)
+
+	if !p.accept(DOT) || !p.accept(IDENT) {
+		return ArchAll, false
+	}
+
+	return archFromSymbol(strings.TrimSuffix(p.currentToken.Literal, "?"))
+}
+
+// parseArchBlock parses the "on_intel do" or "on_arm do" block. The stanzas
+// inside are parsed the same way as inside the if statement and the
+// Parser.currentCaskVariant gets the provided Arch unless the block has no
+// supported stanzas.
+func (p *Parser) parseArchBlock(arch Arch) {
+	p.accept(DO)
+
+	stanzas := p.variantStanzas
+
+	p.insideIfElse = true
+	p.parseBlockStatement()
+
+	if p.variantStanzas != stanzas {
+		p.currentCaskVariant.Arch = arch
+	}
+}
+
 // parseBlockStatement parses the block statement if the Parser.peekToken
 // matches the requirements.
 func (p *Parser) parseBlockStatement(t ...TokenType) {
@@ -351,6 +475,55 @@ func (p *Parser) parseVersion() (*Version, error) {
 	return nil, errors.New("version not found")
 }
 
+// parseSHA256 parses the sha256 if the Parser.peekToken matches the cask
+// requirements.
+func (p *Parser) parseSHA256() (*SHA256, error) {
+	if p.peekTokenIs(STRING) {
+		p.accept(STRING)
+		return NewSHA256(p.currentToken.Literal), nil
+	}
+
+	if p.peekTokenIs(SYMBOL) && p.peekToken.Literal == sha256NoCheck {
+		p.accept(SYMBOL)
+		return NewSHA256(sha256NoCheck), nil
+	}
+
+	return nil, errors.New("sha256 not found")
+}
+
+// parseURL parses the url if the Parser.peekToken matches the cask
+// requirements.
+func (p *Parser) parseURL() (*URL, error) {
+	if p.peekTokenIs(STRING) {
+		p.accept(STRING)
+		return NewURL(p.currentToken.Literal), nil
+	}
+
+	return nil, errors.New("url not found")
+}
+
+// parseName parses the name if the Parser.peekToken matches the cask
+// requirements.
+func (p *Parser) parseName() (*Name, error) {
+	if p.peekTokenIs(STRING) {
+		p.accept(STRING)
+		return NewName(p.currentToken.Literal), nil
+	}
+
+	return nil, errors.New("name not found")
+}
+
+// parseHomepage parses the homepage if the Parser.peekToken matches the cask
+// requirements.
+func (p *Parser) parseHomepage() (*Homepage, error) {
+	if p.peekTokenIs(STRING) {
+		p.accept(STRING)
+		return NewHomepage(p.currentToken.Literal), nil
+	}
+
+	return nil, errors.New("homepage not found")
+}
+
 // parseAppcast parses the appcast if the Parser.peekToken matches the cask
 // requirements. Supports both with and without checkpoint.
 func (p *Parser) parseAppcast() (*Appcast, error) {
@@ -384,6 +557,186 @@ func (p *Parser) parseAppcast() (*Appcast, error) {
 	return nil, errors.New("appcast not found")
 }
 
+// parseLifecycleEvent parses the "deprecate!" or "disable!" stanza arguments:
+// the "date:" and the "because:". The unknown arguments are skipped. Returns
+// an error if the date can't be parsed.
+func (p *Parser) parseLifecycleEvent() (*LifecycleEvent, error) {
+	name := p.currentToken.Literal
+	e := NewLifecycleEvent(time.Time{}, ReasonCustom)
+
+	for p.peekTokenIs(IDENT) {
+		p.accept(IDENT)
+		argument := p.currentToken.Literal
+
+		if !p.peekTokenIs(SYMBOL) || p.peekToken.Literal != "" {
+			return nil, fmt.Errorf("%s argument '%s' has no value", name, argument)
+		}
+		p.accept(SYMBOL)
+
+		switch {
+		case argument == "date" && p.peekTokenIs(STRING):
+			p.accept(STRING)
+			date, err := time.Parse(lifecycleDateLayout, p.currentToken.Literal)
+			if err != nil {
+				return nil, fmt.Errorf("%s date '%s' is invalid", name, p.currentToken.Literal)
+			}
+			e.Date = date
+		case argument == "because" && p.peekTokenIs(SYMBOL):
+			p.accept(SYMBOL)
+
+			// the unknown symbols are kept as the free-form reasons
+			if reason, ok := lifecycleReasonFromSymbol(p.currentToken.Literal); ok {
+				e.Reason = reason
+			} else {
+				e.Message = p.currentToken.Literal
+			}
+		case argument == "because" && p.peekTokenIs(STRING):
+			p.accept(STRING)
+			e.Message = p.currentToken.Literal
+		case p.peekTokenOneOf(STRING, SYMBOL, TRUE, FALSE):
+			p.acceptOneOf(STRING, SYMBOL, TRUE, FALSE)
+		}
+
+		// the arguments can be split into multiple lines after a comma
+		if !p.peekTokenIs(COMMA) {
+			break
+		}
+		p.accept(COMMA)
+
+		if p.peekTokenIs(NEWLINE) {
+			p.accept(NEWLINE)
+		}
+	}
+
+	return e, nil
+}
+
+// parseLivecheck parses the livecheck block if the Parser.peekToken matches
+// the cask requirements. Only the url, strategy and regex statements are
+// parsed, while the rest of the block is skipped.
+func (p *Parser) parseLivecheck() (*Livecheck, error) {
+	if !p.peekTokenIs(DO) {
+		return nil, errors.New("livecheck not found")
+	}
+
+	p.accept(DO)
+
+	l := new(Livecheck)
+	depth := len(p.statements.blocks)
+
+	for {
+		p.nextToken()
+
+		if p.currentTokenIs(ILLEGAL) {
+			p.errors = append(p.errors, fmt.Errorf("%s", p.currentToken.Literal))
+		}
+
+		if p.currentTokenOneOf(EOF, ILLEGAL) {
+			return nil, errors.New("livecheck block is not closed")
+		}
+
+		// the "end" closing the livecheck block
+		if len(p.statements.blocks) < depth {
+			return l, nil
+		}
+
+		if len(p.statements.blocks) > depth || !p.statements.isStatementStart() || !p.currentTokenIs(IDENT) {
+			continue
+		}
+
+		switch p.currentToken.Literal {
+		case "url":
+			p.parseLivecheckURL(l)
+		case "strategy":
+			p.parseLivecheckStrategy(l)
+		case "regex":
+			p.parseLivecheckRegex(l)
+		}
+	}
+}
+
+// parseLivecheckURL parses the livecheck url statement: either the URL string
+// or the ":url" and ":homepage" symbol references.
+func (p *Parser) parseLivecheckURL(l *Livecheck) {
+	switch {
+	case p.peekTokenIs(STRING):
+		p.accept(STRING)
+		l.URL = p.currentToken.Literal
+	case p.peekTokenIs(SYMBOL):
+		p.accept(SYMBOL)
+		l.URLReference = p.currentToken.Literal
+	}
+}
+
+// parseLivecheckStrategy parses the livecheck strategy statement including
+// the custom "do |page| ... end" block, which is captured as raw source. The
+// unknown strategies are kept as raw symbols and reported as warnings.
+func (p *Parser) parseLivecheckStrategy(l *Livecheck) {
+	if !p.peekTokenIs(SYMBOL) {
+		return
+	}
+
+	p.accept(SYMBOL)
+
+	strategy, ok := livecheckStrategyFromSymbol(p.currentToken.Literal)
+	if !ok {
+		// the symbol position excludes the colon
+		p.warnings = append(p.warnings, fmt.Errorf(
+			"%s: unknown livecheck strategy '%s'",
+			NewPosition(p.lexer.input, p.currentToken.Position-1),
+			p.currentToken.Literal,
+		))
+		l.UnknownStrategy = p.currentToken.Literal
+	}
+	l.Strategy = strategy
+
+	if !p.peekTokenIs(DO) {
+		return
+	}
+
+	start := p.peekToken.Position
+	p.accept(DO)
+
+	depth := len(p.statements.blocks)
+	for len(p.statements.blocks) >= depth {
+		if p.peekTokenOneOf(EOF, ILLEGAL) {
+			return
+		}
+
+		p.nextToken()
+	}
+
+	l.HasStrategyBlock = true
+	l.StrategyBlock = p.lexer.input[start : p.currentToken.Position+len(p.currentToken.Literal)]
+}
+
+// parseLivecheckRegex parses the livecheck regex statement. Both the
+// "/regex/" literals and the "%r{regex}" percent notation are supported.
+func (p *Parser) parseLivecheckRegex(l *Livecheck) {
+	if p.peekTokenIs(LPAREN) {
+		p.accept(LPAREN)
+	}
+
+	if p.peekTokenIs(PNREGEXP) {
+		p.accept(PNREGEXP)
+	}
+
+	if !p.peekTokenIs(PNSTART) {
+		return
+	}
+
+	p.accept(PNSTART)
+	p.accept(REGEXP)
+	l.Regex = p.currentToken.Literal
+	p.accept(PNEND)
+
+	// the flags directly follow the closing delimiter
+	if p.peekTokenIs(IDENT) && p.peekToken.Position == p.currentToken.Position+1 {
+		p.accept(IDENT)
+		l.RegexFlags = p.currentToken.Literal
+	}
+}
+
 // ParseArtifact parses the supported artifact if the Parser.currentToken
 // literal value matches the supported one. It runs the corresponding artifact
 // specific parsing function. Returns an "artifact not found" error if the
@@ -504,14 +857,47 @@ func (p *Parser) parseArtifactBinary() (*Artifact, error) {
 }
 
 // ParseConditionMacOS parses the "MacOS.version" condition statement. Returns
-// both the minimum and maximum macOS releases. By default, the minimum is
-// MacOSTiger and the maximum matches the latest macOS release which is
-// MacOSHighSierra.
+// both the minimum and maximum macOS releases. By default, the minimum is the
+// oldest targeted macOS release (MacOSTiger) and the maximum matches the latest
+// one (MacOSHighSierra). The targeted releases can be changed using the
+// WithMacOSReleases option. The comparisons joined by "&&" are combined into
+// the releases supported by all of them, for example: "MacOS.version >=
+// :el_capitan && MacOS.version <= :sierra".
 func (p *Parser) ParseConditionMacOS() (min MacOS, max MacOS, err error) {
+	min, max, err = p.parseConditionMacOSComparison()
+
+	for err == nil && p.peekTokenIs(AND) {
+		p.accept(AND)
+		p.nextToken()
+
+		otherMin, otherMax, err := p.parseConditionMacOSComparison()
+		if err != nil {
+			return otherMin, otherMax, err
+		}
+
+		// the lower MacOS values represent the newer releases
+		if otherMin < min {
+			min = otherMin
+		}
+
+		if otherMax > max {
+			max = otherMax
+		}
+	}
+
+	return min, max, err
+}
+
+// parseConditionMacOSComparison parses a single "MacOS.version" comparison.
+// Returns both the minimum and maximum macOS releases.
+func (p *Parser) parseConditionMacOSComparison() (min MacOS, max MacOS, err error) {
 	var comparison TokenType
 	var hasEqual bool
 	var mac MacOS
 
+	latest := p.options.LatestMacOS()
+	oldest := p.options.OldestMacOS()
+
 	if p.currentTokenIs(CONST) && p.currentToken.Literal == "MacOS" {
 		p.accept(DOT)
 
@@ -533,29 +919,9 @@ func (p *Parser) ParseConditionMacOS() (min MacOS, max MacOS, err error) {
 			// macOS
 			if p.peekTokenIs(SYMBOL) {
 				p.accept(SYMBOL)
-				switch p.currentToken.Literal {
-				case "high_sierra":
-					mac = MacOSHighSierra
-				case "sierra":
-					mac = MacOSSierra
-				case "el_capitan":
-					mac = MacOSElCapitan
-				case "yosemite":
-					mac = MacOSYosemite
-				case "mavericks":
-					mac = MacOSMavericks
-				case "mountain_lion":
-					mac = MacOSMountainLion
-				case "lion":
-					mac = MacOSLion
-				case "snow_leopard":
-					mac = MacOSSnowLeopard
-				case "leopard":
-					mac = MacOSLeopard
-				case "tiger":
-					mac = MacOSTiger
-				default:
-					return MacOSHighSierra, MacOSHighSierra, errors.New("MacOS condition is unknown")
+				var ok bool
+				if mac, ok = macOSFromSymbol(p.currentToken.Literal); !ok {
+					return latest, latest, errors.New("MacOS condition is unknown")
 				}
 			}
 
@@ -565,15 +931,15 @@ func (p *Parser) ParseConditionMacOS() (min MacOS, max MacOS, err error) {
 				return mac, mac, nil
 			case GT:
 				min = mac - 1
-				max = MacOSHighSierra
-				if hasEqual || min < 0 {
+				max = latest
+				if hasEqual || min < latest {
 					min = mac
 				}
 				return min, max, nil
 			case LT:
-				min = MacOSTiger
+				min = oldest
 				max = mac + 1
-				if hasEqual || max > MacOSTiger {
+				if hasEqual || max > oldest {
 					max = mac
 				}
 				return min, max, nil
@@ -582,7 +948,31 @@ func (p *Parser) ParseConditionMacOS() (min MacOS, max MacOS, err error) {
 	}
 
 	// by default should return the latest
-	return MacOSHighSierra, MacOSHighSierra, errors.New("MacOS condition not found")
+	return latest, latest, errors.New("MacOS condition not found")
+}
+
+// interpolationVariables returns the interpolation variables for the parsed
+// cask variants: the ParseOptions.InterpolationVariables and the cask "token".
+func (p *Parser) interpolationVariables() map[string]string {
+	variables := map[string]string{
+		VariableToken: p.cask.Token,
+	}
+
+	for name, value := range p.options.InterpolationVariables {
+		variables[name] = value
+	}
+
+	return variables
+}
+
+// newVariant creates a new Variant instance which supports only the latest
+// targeted macOS release and returns its pointer.
+func (p *Parser) newVariant() *Variant {
+	v := NewVariant()
+	v.MinimumSupportedMacOS = p.options.LatestMacOS()
+	v.MaximumSupportedMacOS = p.options.LatestMacOS()
+
+	return v
 }
 
 // mergeCurrentCaskVariantIfNotEmpty is a Parser.mergeCurrentCaskVariant
@@ -605,18 +995,52 @@ func (p *Parser) mergeCurrentCaskVariantIfNotEmpty(i interface{}) {
 func (p *Parser) mergeCurrentCaskVariant(condition bool) {
 	if condition {
 		p.cask.AddVariant(p.currentCaskVariant)
-		p.currentCaskVariant = NewVariant()
+		p.currentCaskVariant = p.newVariant()
 	}
 }
 
+// unknownStanza reports the Parser.currentToken as an unknown stanza. In the
+// ModeStrict it's added to the Parser.errors, otherwise to the
+// Parser.warnings.
+func (p *Parser) unknownStanza() {
+	err := &UnknownStanzaError{
+		Stanza:   p.currentToken.Literal,
+		Position: *NewPosition(p.lexer.input, p.currentToken.Position),
+	}
+
+	if p.options.Mode == ModeStrict {
+		p.errors = append(p.errors, err)
+		return
+	}
+
+	p.warnings = append(p.warnings, err)
+}
+
+// stanzaError reports the error returned by the StanzaParser of the stanza
+// with the provided name and position. In the ModeStrict it's added to the
+// Parser.errors, otherwise to the Parser.warnings.
+func (p *Parser) stanzaError(name string, position int, err error) {
+	err = errors.Wrapf(err, "%s: could not parse '%s' stanza", NewPosition(p.lexer.input, position), name)
+
+	if p.options.Mode == ModeStrict {
+		p.errors = append(p.errors, err)
+		return
+	}
+
+	p.warnings = append(p.warnings, err)
+}
+
 // nextToken updates the Parser.currentToken and Parser.peekToken values to
-// match the next Lexer token. If Lexer doesn't have any token left, returns the
-// "No tokens left" error.
+// match the next Lexer token. If Lexer doesn't have any token left, the
+// Parser.peekToken becomes EOF and the "No tokens left" error is returned.
 func (p *Parser) nextToken() error {
 	p.currentToken = p.peekToken
-	if p.lexer.HasNext() {
-		p.peekToken = p.lexer.NextToken()
-	} else {
+	p.statements.track(p.currentToken)
+
+	hasNext := p.lexer.HasNext()
+	p.peekToken = p.lexer.NextToken()
+
+	if !hasNext {
 		return errors.New("No tokens left")
 	}
 
@@ -704,7 +1128,53 @@ func (p *Parser) peekError(t ...TokenType) {
 	})
 }
 
+// stanzaRegistry returns the StanzaRegistry from the Parser options. By
+// default, it's the DefaultStanzaRegistry.
+func (p *Parser) stanzaRegistry() *StanzaRegistry {
+	if p.options.StanzaRegistry != nil {
+		return p.options.StanzaRegistry
+	}
+
+	return DefaultStanzaRegistry
+}
+
+// CurrentToken returns the current Lexer token. Should be used by the
+// StanzaParser functions to inspect the parsing state.
+func (p *Parser) CurrentToken() Token {
+	return p.currentToken
+}
+
+// PeekToken returns the next Lexer token. Should be used by the StanzaParser
+// functions to inspect the parsing state.
+func (p *Parser) PeekToken() Token {
+	return p.peekToken
+}
+
+// NextToken moves the Parser to the next Lexer token. Returns the "No tokens
+// left" error if Lexer doesn't have any token left.
+func (p *Parser) NextToken() error {
+	return p.nextToken()
+}
+
+// Accept moves the Parser to the next Lexer token if it matches the specified
+// TokenType. Otherwise, the unexpected token error is added to the
+// Parser.errors and false is returned.
+func (p *Parser) Accept(t TokenType) bool {
+	return p.accept(t)
+}
+
+// InsideIf checks whether the Parser is currently inside of the if statement.
+func (p *Parser) InsideIf() bool {
+	return p.insideIfElse
+}
+
 // Errors returns all errors which happened during the Parser.input parsing.
 func (p *Parser) Errors() []error {
 	return p.errors
 }
+
+// Warnings returns all warnings which happened during the Parser.input
+// parsing.
+func (p *Parser) Warnings() []error {
+	return p.warnings
+}
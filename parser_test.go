@@ -3,6 +3,7 @@ package cask
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -35,6 +36,55 @@ func TestNewParser(t *testing.T) {
 	assert.Len(t, p.errors, 0)
 }
 
+func TestSetMode(t *testing.T) {
+	// preparations
+	p := createCaskTestParser()
+
+	// test
+	assert.Equal(t, ModeLenient, p.options.Mode)
+	p.SetMode(ModeStrict)
+	assert.Equal(t, ModeStrict, p.options.Mode)
+}
+
+func TestParseCaskUnknownStanzas(t *testing.T) {
+	expected := []string{
+		"8:3: unknown stanza 'hompage'",
+		"11:5: unknown stanza 'auto_updates'",
+		"16:3: unknown stanza 'postflight'",
+		"20:3: unknown stanza 'zap'",
+	}
+
+	// test (lenient)
+	c := NewCask(string(getTestdata("unknown-stanzas.rb")))
+	err := c.Parse()
+	assert.Nil(t, err)
+	assert.Len(t, c.Warnings, len(expected))
+	for i, w := range c.Warnings {
+		assert.IsType(t, &UnknownStanzaError{}, w)
+		assert.Equal(t, expected[i], w.Error())
+	}
+
+	// test (strict)
+	c = NewCask(string(getTestdata("unknown-stanzas.rb")))
+	c.parser.SetMode(ModeStrict)
+	err = c.Parse()
+	assert.Error(t, err)
+	assert.Len(t, c.Warnings, 0)
+	assert.Len(t, c.parser.Errors(), len(expected))
+	for i, e := range c.parser.Errors() {
+		assert.IsType(t, &UnknownStanzaError{}, e)
+		assert.Equal(t, expected[i], e.Error())
+	}
+	assert.Equal(t, "unknown-stanzas", c.Token)
+	assert.Equal(t, "https://example.com/app_1.0.0.dmg", c.Variants[0].GetURL().Value)
+
+	// test (without unknown stanzas)
+	c = NewCask(string(getTestdata("latest.rb")))
+	c.parser.SetMode(ModeStrict)
+	assert.Nil(t, c.Parse())
+	assert.Len(t, c.Warnings, 0)
+}
+
 func TestParseStatement(t *testing.T) {
 	testCases := map[string]interface{}{
 		// token
@@ -88,7 +138,7 @@ func TestParseIfExpression(t *testing.T) {
 		"if MacOS.version == :tiger then; five = 5; end": nil,
 
 		// errors
-		"if": {EOF, "", 0},
+		"if":                         {EOF, "", 0},
 		"if MacOS.version == :tiger": {EOF, "", 0},
 
 		// unknown conditions
@@ -213,6 +263,117 @@ func TestParseAppcast(t *testing.T) {
 	}
 }
 
+func TestParseLivecheck(t *testing.T) {
+	// test (successful)
+	testCases := map[string]Livecheck{
+		"livecheck do\n  url 'https://example.com/appcast.xml'\n  strategy :sparkle\nend": {
+			URL:      "https://example.com/appcast.xml",
+			Strategy: StrategySparkle,
+		},
+		"livecheck do\n  url :url\n  regex(%r{/app-(\\d+(?:\\.\\d+)+)\\.dmg}i)\nend": {
+			URLReference: "url",
+			Regex:        `/app-(\d+(?:\.\d+)+)\.dmg`,
+			RegexFlags:   "i",
+		},
+		"livecheck do\n  url :homepage\n  regex /app_(\\d+)\\.dmg/\n  skip 'unversioned'\nend": {
+			URLReference: "homepage",
+			Regex:        `app_(\d+)\.dmg`,
+		},
+		"livecheck do\n  strategy :header_match do |headers|\n    headers['location'][/(\\d+)/, 1]\n  end\nend": {
+			Strategy:         StrategyHeaderMatch,
+			HasStrategyBlock: true,
+			StrategyBlock:    "do |headers|\n    headers['location'][/(\\d+)/, 1]\n  end",
+		},
+	}
+
+	for testCase, expected := range testCases {
+		// preparations
+		l := NewLexer(testCase)
+		p := NewParser(l)
+
+		// test
+		actual, err := p.parseLivecheck()
+		assert.Nil(t, err, testCase)
+		assert.Equal(t, expected, *actual, testCase)
+		assert.True(t, p.currentTokenIs(END), testCase)
+		assert.True(t, p.peekTokenIs(EOF), testCase)
+		assert.Empty(t, p.Warnings(), testCase)
+	}
+
+	// test (warning)
+	p := NewParser(NewLexer("livecheck do\n  strategy :unknown\nend"))
+	actual, err := p.parseLivecheck()
+	assert.Nil(t, err)
+	assert.Equal(t, StrategyDefault, actual.Strategy)
+	assert.Equal(t, "unknown", actual.UnknownStrategy)
+	assert.EqualError(t, p.Warnings()[0], "2:12: unknown livecheck strategy 'unknown'")
+
+	// test (error)
+	testCasesErrors := map[string]string{
+		"livecheck":                       "livecheck not found",
+		"livecheck do\n  url :homepage\n": "livecheck block is not closed",
+	}
+
+	for testCase, expected := range testCasesErrors {
+		// preparations
+		l := NewLexer(testCase)
+		p := NewParser(l)
+
+		// test
+		actual, err := p.parseLivecheck()
+		assert.Nil(t, actual)
+		assert.EqualError(t, err, expected)
+	}
+}
+
+func TestParseLifecycleEvent(t *testing.T) {
+	// test (successful)
+	testCases := map[string]LifecycleEvent{
+		"deprecate! date: '2023-01-01', because: :discontinued": {
+			Date:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			Reason: ReasonDiscontinued,
+		},
+		"disable! date: '2024-02-29',\n         because: 'is no longer supported'": {
+			Date:    time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			Message: "is no longer supported",
+		},
+		"deprecate! because: :unknown_reason, replacement_cask: 'example'": {
+			Message: "unknown_reason",
+		},
+		"deprecate!": {},
+	}
+
+	for testCase, expected := range testCases {
+		// preparations
+		l := NewLexer(testCase)
+		p := NewParser(l)
+
+		// test
+		actual, err := p.parseLifecycleEvent()
+		assert.Nil(t, err, testCase)
+		assert.Equal(t, expected, *actual, testCase)
+		assert.True(t, p.peekTokenIs(EOF), testCase)
+	}
+
+	// test (error)
+	testCasesErrors := map[string]string{
+		"deprecate! date: '2023-13-01'": "deprecate! date '2023-13-01' is invalid",
+		"disable! date: '01.01.2023'":   "disable! date '01.01.2023' is invalid",
+		"disable! date":                 "disable! argument 'date' has no value",
+	}
+
+	for testCase, expected := range testCasesErrors {
+		// preparations
+		l := NewLexer(testCase)
+		p := NewParser(l)
+
+		// test
+		actual, err := p.parseLifecycleEvent()
+		assert.Nil(t, actual)
+		assert.EqualError(t, err, expected)
+	}
+}
+
 func TestParseArtifactApp(t *testing.T) {
 	// test (successful)
 	testCases := map[string]Artifact{
@@ -421,6 +582,11 @@ func TestParseConditionMacOS(t *testing.T) {
 		// LT and EQ (<=)
 		"MacOS.version <= :el_capitan": {MacOSTiger, MacOSElCapitan},
 		"MacOS.version <= :tiger":      {MacOSTiger, MacOSTiger},
+
+		// AND (&&)
+		"MacOS.version >= :el_capitan && MacOS.version <= :sierra": {MacOSElCapitan, MacOSSierra},
+		"MacOS.version < :sierra && MacOS.version > :yosemite":     {MacOSElCapitan, MacOSElCapitan},
+		"MacOS.version >= :sierra && MacOS.version <= :yosemite":   {MacOSSierra, MacOSYosemite},
 	}
 
 	for testCase, expected := range testCases {
@@ -439,6 +605,8 @@ func TestParseConditionMacOS(t *testing.T) {
 	testCasesErrors := map[string]string{
 		"MacOS.version == :invalid": "MacOS condition is unknown",
 		"invalid":                   "MacOS condition not found",
+		"MacOS.version >= :sierra && MacOS.version == :invalid": "MacOS condition is unknown",
+		"MacOS.version >= :sierra && invalid":                   "MacOS condition not found",
 	}
 
 	for testCase, expected := range testCasesErrors {
@@ -455,6 +623,51 @@ func TestParseConditionMacOS(t *testing.T) {
 	}
 }
 
+func TestParseConditionArch(t *testing.T) {
+	testCases := map[string]Arch{
+		"Hardware::CPU.intel?": ArchIntel,
+		"Hardware::CPU.arm?":   ArchARM,
+	}
+
+	for input, expected := range testCases {
+		// preparations
+		p := NewParser(NewLexer(input))
+
+		// test
+		arch, ok := p.parseConditionArch()
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, arch, input)
+	}
+
+	// test (unknown)
+	for _, input := range []string{"Hardware::CPU.ppc?", "Hardware::Memory.size", "MacOS.version"} {
+		p := NewParser(NewLexer(input))
+		_, ok := p.parseConditionArch()
+		assert.False(t, ok, input)
+	}
+}
+
+func TestParseCaskArch(t *testing.T) {
+	testCases := map[string][]Arch{
+		"if-arch.rb": {ArchIntel, ArchARM},
+		"on-arch.rb": {ArchIntel, ArchARM},
+	}
+
+	for filename, expected := range testCases {
+		// preparations
+		c := NewCask(string(getTestdata(filename)))
+
+		// test
+		assert.Nil(t, c.Parse(), filename)
+		assert.Empty(t, c.Warnings, filename)
+		assert.Len(t, c.Variants, len(expected), filename)
+		for i, v := range c.Variants {
+			assert.Equal(t, expected[i], v.Arch, filename)
+			assert.Equal(t, "https://example.com/", v.GetHomepage().Value, filename)
+		}
+	}
+}
+
 func TestMergeCurrentCaskVariantIfNotEmpty(t *testing.T) {
 	// preparations
 	p := createCaskTestParser()
@@ -587,3 +800,66 @@ func TestParserErrors(t *testing.T) {
 	// test
 	assert.IsType(t, []error{}, p.Errors())
 }
+
+func TestParserWarnings(t *testing.T) {
+	// preparations
+	p := createCaskTestParser()
+
+	// test
+	assert.IsType(t, []error{}, p.Warnings())
+}
+
+func TestParseSimpleStanzas(t *testing.T) {
+	// preparations
+	p := NewParser(NewLexer("sha256 'test'\nurl 'test'\nname 'test'\nhomepage 'test'"))
+
+	// test
+	s, err := p.parseSHA256()
+	assert.Nil(t, err)
+	assert.Equal(t, "test", s.Value)
+
+	p.nextToken()
+	p.nextToken()
+	u, err := p.parseURL()
+	assert.Nil(t, err)
+	assert.Equal(t, "test", u.Value)
+
+	p.nextToken()
+	p.nextToken()
+	n, err := p.parseName()
+	assert.Nil(t, err)
+	assert.Equal(t, "test", n.Value)
+
+	p.nextToken()
+	p.nextToken()
+	h, err := p.parseHomepage()
+	assert.Nil(t, err)
+	assert.Equal(t, "test", h.Value)
+
+	// test (no check)
+	p = NewParser(NewLexer("sha256 :no_check"))
+	s, err = p.parseSHA256()
+	assert.Nil(t, err)
+	assert.Equal(t, "no_check", s.Value)
+
+	// test (error)
+	p = NewParser(NewLexer("sha256 :unknown"))
+	s, err = p.parseSHA256()
+	assert.Nil(t, s)
+	assert.EqualError(t, err, "sha256 not found")
+}
+
+func TestParserExportedTokenMethods(t *testing.T) {
+	// preparations
+	p := createTokenTestParser()
+
+	// test
+	assert.Equal(t, IDENT, p.CurrentToken().Type)
+	assert.Equal(t, ASSIGN, p.PeekToken().Type)
+	assert.Nil(t, p.NextToken())
+	assert.Equal(t, ASSIGN, p.CurrentToken().Type)
+	assert.True(t, p.Accept(INT))
+	assert.Equal(t, "5", p.CurrentToken().Literal)
+	assert.False(t, p.Accept(INT))
+	assert.False(t, p.InsideIf())
+}
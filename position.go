@@ -0,0 +1,46 @@
+package cask
+
+import "fmt"
+
+// A Position represents a human-readable position in the cask content.
+type Position struct {
+	// Offset specifies the byte offset starting at 0.
+	Offset int
+
+	// Line specifies the line number starting at 1.
+	Line int
+
+	// Column specifies the column number (in bytes) starting at 1.
+	Column int
+}
+
+// NewPosition creates a new Position instance from the specified content and
+// byte offset and returns its pointer. If the offset is out of the content
+// bounds, it's clamped to the nearest one.
+func NewPosition(content string, offset int) *Position {
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset > len(content) {
+		offset = len(content)
+	}
+
+	p := &Position{Offset: offset, Line: 1, Column: 1}
+	for i := 0; i < offset; i++ {
+		if content[i] == '\n' {
+			p.Line++
+			p.Column = 1
+			continue
+		}
+		p.Column++
+	}
+
+	return p
+}
+
+// String returns a string representation of the Position in the "line:column"
+// format.
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
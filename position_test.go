@@ -0,0 +1,33 @@
+package cask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPosition(t *testing.T) {
+	content := "cask 'example' do\n  version '1.0.0'\nend\n"
+
+	testCases := map[int]Position{
+		-1:  {0, 1, 1},
+		0:   {0, 1, 1},
+		5:   {5, 1, 6},
+		18:  {18, 2, 1},
+		20:  {20, 2, 3},
+		100: {len(content), 4, 1},
+	}
+
+	for offset, expected := range testCases {
+		// preparations
+		p := NewPosition(content, offset)
+
+		// test
+		assert.IsType(t, Position{}, *p)
+		assert.Equal(t, expected, *p)
+	}
+}
+
+func TestPositionString(t *testing.T) {
+	assert.Equal(t, "2:3", Position{20, 2, 3}.String())
+}
@@ -0,0 +1,143 @@
+package cask
+
+import "fmt"
+
+// A ResolvedVariant represents a single Variant flattened into plain values.
+// All interpolations are resolved and the global stanzas are inherited, so it
+// can be used directly in templates or encoded as JSON.
+type ResolvedVariant struct {
+	// Token specifies the cask token.
+	Token string `json:"token"`
+
+	// Version specifies the version stanza value. For example, "2.0.0" or
+	// "latest".
+	Version string `json:"version"`
+
+	// SHA256 specifies the sha256 stanza value or "no_check" if the checksum
+	// isn't checked. It's empty if the Variant doesn't have one.
+	SHA256 string `json:"sha256"`
+
+	// URL specifies the url stanza value.
+	URL string `json:"url"`
+
+	// Appcast specifies the appcast stanza URL.
+	Appcast string `json:"appcast,omitempty"`
+
+	// Livecheck specifies the livecheck stanza. It's nil if the Variant doesn't
+	// have one.
+	Livecheck *LivecheckSchema `json:"livecheck,omitempty"`
+
+	// Names specify the application names.
+	Names []string `json:"names"`
+
+	// Desc specifies the "desc" stanza value if it has been parsed by a custom
+	// StanzaParser.
+	Desc string `json:"desc,omitempty"`
+
+	// Homepage specifies the homepage stanza value.
+	Homepage string `json:"homepage"`
+
+	// Artifacts specify the artifact stanzas.
+	Artifacts []ResolvedArtifact `json:"artifacts"`
+
+	// Arch specifies the supported CPU architecture.
+	Arch Arch `json:"arch"`
+
+	// MinimumMacOS specifies the minimum supported macOS release.
+	MinimumMacOS MacOS `json:"minimum_macos"`
+
+	// MinimumMacOSVersion specifies the minimum supported macOS release
+	// version. For example, "10.12".
+	MinimumMacOSVersion string `json:"minimum_macos_version"`
+
+	// MaximumMacOS specifies the maximum supported macOS release.
+	MaximumMacOS MacOS `json:"maximum_macos"`
+
+	// MaximumMacOSVersion specifies the maximum supported macOS release
+	// version. For example, "10.13".
+	MaximumMacOSVersion string `json:"maximum_macos_version"`
+}
+
+// A ResolvedArtifact represents a single Artifact with the resolved
+// interpolations.
+type ResolvedArtifact struct {
+	// Type specifies the artifact stanza name. For example, "app".
+	Type string `json:"type"`
+
+	// Value specifies the artifact value.
+	Value string `json:"value"`
+
+	// Target specifies the "target:" value.
+	Target string `json:"target,omitempty"`
+
+	// AllowUntrusted specifies the "allow_untrusted:" value.
+	AllowUntrusted bool `json:"allow_untrusted,omitempty"`
+}
+
+// String returns the string representation of the ResolvedArtifact in the same
+// format as the Artifact.String.
+func (a ResolvedArtifact) String() string {
+	t, ok := artifactTypeFromName(a.Type)
+	if !ok {
+		return fmt.Sprintf("%s, %s", a.Type, a.Value)
+	}
+
+	return Artifact{t, a.Value, a.Target, a.AllowUntrusted}.String()
+}
+
+// Resolve returns all Cask.Variants flattened into the ResolvedVariant values
+// in the same order. Unless specified during parsing, the "#{arch}"
+// interpolations are resolved only for the variants with a specific
+// Variant.Arch.
+func (c *Cask) Resolve() []ResolvedVariant {
+	resolved := make([]ResolvedVariant, 0, len(c.Variants))
+	for _, v := range c.Variants {
+		resolved = append(resolved, newResolvedVariant(c.Token, v.resolved(ArchAll)))
+	}
+
+	return resolved
+}
+
+// newResolvedVariant creates a new ResolvedVariant from the provided Variant,
+// which interpolations are already resolved.
+func newResolvedVariant(token string, v *Variant) ResolvedVariant {
+	r := ResolvedVariant{
+		Token:               token,
+		Version:             v.GetVersion().Value,
+		SHA256:              v.GetSHA256().Value,
+		URL:                 v.GetURL().Value,
+		Appcast:             v.GetAppcast().URL,
+		Names:               make([]string, 0, len(v.Names)),
+		Homepage:            v.GetHomepage().Value,
+		Artifacts:           make([]ResolvedArtifact, 0, len(v.Artifacts)),
+		Arch:                v.Arch,
+		MinimumMacOS:        v.MinimumSupportedMacOS,
+		MinimumMacOSVersion: v.MinimumSupportedMacOS.Version(),
+		MaximumMacOS:        v.MaximumSupportedMacOS,
+		MaximumMacOSVersion: v.MaximumSupportedMacOS.Version(),
+	}
+
+	if v.Livecheck != nil {
+		livecheck := newLivecheckSchema(v.GetLivecheck())
+		r.Livecheck = &livecheck
+	}
+
+	for _, n := range v.GetNames() {
+		r.Names = append(r.Names, n.Value)
+	}
+
+	if desc := v.GetStanzas("desc"); len(desc) > 0 {
+		r.Desc = v.interpolate(desc[0].String())
+	}
+
+	for _, a := range v.GetArtifacts() {
+		r.Artifacts = append(r.Artifacts, ResolvedArtifact{
+			Type:           a.Type.String(),
+			Value:          a.Value,
+			Target:         a.Target,
+			AllowUntrusted: a.AllowUntrusted,
+		})
+	}
+
+	return r
+}
@@ -0,0 +1,100 @@
+package cask
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("if-global-version-last.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	resolved := c.Resolve()
+	assert.Len(t, resolved, 2)
+
+	r := resolved[0]
+	assert.Equal(t, "if-global-version-last", r.Token)
+	assert.Equal(t, "2.0.0", r.Version)
+	assert.Equal(t, "cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435", r.SHA256)
+	assert.Equal(t, "https://example.com/app_2.0.0_mac32.dmg", r.URL)
+	assert.Equal(t, "https://example.com/sparkle/2/appcast.xml", r.Appcast)
+	assert.Nil(t, r.Livecheck)
+	assert.Equal(t, []string{"Example", "Example (if-global-version-last)"}, r.Names)
+	assert.Equal(t, "https://example.com/", r.Homepage)
+	assert.Equal(t, []ResolvedArtifact{
+		{Type: "app", Value: "Example (if-global-version-last).app", Target: "Example.app"},
+		{Type: "binary", Value: "/Applications/Example.app/Contents/MacOS/example-if", Target: "example"},
+	}, r.Artifacts)
+	assert.Equal(t, ArchAll, r.Arch)
+	assert.Equal(t, MacOSTiger, r.MinimumMacOS)
+	assert.Equal(t, "10.4", r.MinimumMacOSVersion)
+	assert.Equal(t, MacOSLeopard, r.MaximumMacOS)
+	assert.Equal(t, "10.5", r.MaximumMacOSVersion)
+
+	r = resolved[1]
+	assert.Equal(t, "https://example.com/app_2.0.0_mac64.dmg", r.URL)
+	assert.Equal(t, MacOSHighSierra, r.MinimumMacOS)
+	assert.Equal(t, "10.13", r.MaximumMacOSVersion)
+
+	// test (original variants aren't changed)
+	assert.Equal(t, "https://example.com/app_#{version}_mac32.dmg", c.Variants[0].URL.Value)
+}
+
+func TestResolveArch(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("on-arch.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	resolved := c.Resolve()
+	assert.Len(t, resolved, 2)
+	assert.Equal(t, ArchIntel, resolved[0].Arch)
+	assert.Equal(t, "https://example.com/app_1.0.0_x86_64.dmg", resolved[0].URL)
+	assert.Equal(t, ArchARM, resolved[1].Arch)
+	assert.Equal(t, "https://example.com/app_2.0.0_arm64.dmg", resolved[1].URL)
+}
+
+func TestResolveLivecheck(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("livecheck.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	resolved := c.Resolve()
+	assert.Len(t, resolved, 1)
+	assert.NotNil(t, resolved[0].Livecheck)
+	assert.Equal(t, "page_match", *resolved[0].Livecheck.Strategy)
+}
+
+func TestResolvedVariantJSON(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("on-arch.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	data, err := json.Marshal(c.Resolve()[1])
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"token": "on-arch",
+		"version": "2.0.0",
+		"sha256": "9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7",
+		"url": "https://example.com/app_2.0.0_arm64.dmg",
+		"names": ["Example"],
+		"homepage": "https://example.com/",
+		"artifacts": [{"type": "app", "value": "Example.app"}],
+		"arch": "arm",
+		"minimum_macos": "high_sierra",
+		"minimum_macos_version": "10.13",
+		"maximum_macos": "high_sierra",
+		"maximum_macos_version": "10.13"
+	}`, string(data))
+
+	// test (decoding)
+	var r ResolvedVariant
+	assert.Nil(t, json.Unmarshal(data, &r))
+	assert.Equal(t, c.Resolve()[1], r)
+}
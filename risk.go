@@ -0,0 +1,176 @@
+package cask
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A RiskSeverity represents the Risk severity level.
+type RiskSeverity int
+
+// Different risk severities.
+const (
+	RiskLow RiskSeverity = iota
+	RiskMedium
+	RiskHigh
+)
+
+var riskSeverityNames = [...]string{
+	"low",
+	"medium",
+	"high",
+}
+
+// hookStanzas specify the stanzas, which code is run during the installation
+// or uninstallation.
+var hookStanzas = map[string]bool{
+	"preflight":            true,
+	"postflight":           true,
+	"uninstall_preflight":  true,
+	"uninstall_postflight": true,
+}
+
+// uninstallStanzas specify the stanzas, which directives can run scripts.
+var uninstallStanzas = map[string]bool{
+	"uninstall": true,
+	"zap":       true,
+}
+
+// A Risk represents a single security-relevant Change.
+type Risk struct {
+	// Change specifies the classified change.
+	Change Change `json:"change"`
+
+	// Severity specifies the risk severity.
+	Severity RiskSeverity `json:"severity"`
+
+	// Reason specifies the human-readable explanation why the change is risky.
+	Reason string `json:"reason"`
+}
+
+// ClassifyRisks returns the risks found in the provided changes returned by
+// the Diff in the same order. The changes that aren't risky are skipped and a
+// single Change can have multiple risks. The content of the added variants is
+// classified the same way, as the Diff compares it with the variant used
+// before. The following changes are classified:
+//
+//   - the download host change (RiskHigh)
+//   - the sha256 switched to ":no_check" (RiskHigh)
+//   - the sha256 change without a version change (RiskMedium)
+//   - the "allow_untrusted: true" added to a pkg artifact (RiskHigh)
+//   - the new "preflight" or "postflight" code (RiskHigh) or its change
+//     (RiskMedium), including the uninstall ones
+//   - the new "script:" or "sudo:" usage in the "uninstall" or "zap"
+//     (RiskHigh)
+//   - the version downgrade (RiskLow)
+func ClassifyRisks(changes []Change) []Risk {
+	risks := make([]Risk, 0)
+
+	versions := make(map[string]bool)
+	removedPkgs := make(map[string]bool)
+	for _, c := range changes {
+		switch c.Kind {
+		case ChangeVersion:
+			versions[c.Variant] = true
+		case ChangeArtifactRemoved:
+			if c.Artifact != nil && c.Artifact.Type == ArtifactPkg.String() && c.Artifact.AllowUntrusted {
+				removedPkgs[c.Variant+"\x00"+c.Artifact.Value] = true
+			}
+		}
+	}
+
+	for _, c := range changes {
+		add := func(severity RiskSeverity, format string, args ...interface{}) {
+			risks = append(risks, Risk{c, severity, fmt.Sprintf(format, args...)})
+		}
+
+		switch c.Kind {
+		case ChangeURLHost:
+			if c.Old != "" && c.New != "" {
+				add(RiskHigh, "download host changed from '%s' to '%s'", urlHost(c.Old), urlHost(c.New))
+			}
+		case ChangeChecksum:
+			switch {
+			case c.New == sha256NoCheck:
+				add(RiskHigh, "sha256 checksum verification is disabled")
+			case c.Old != "" && c.Old != sha256NoCheck && c.New != "" && !versions[c.Variant]:
+				add(RiskMedium, "sha256 checksum changed without a version change")
+			}
+		case ChangeVersion:
+			if NewVersion(c.New).LessThan(*NewVersion(c.Old)) {
+				add(RiskLow, "version downgraded from '%s' to '%s'", c.Old, c.New)
+			}
+		case ChangeArtifactAdded:
+			a := c.Artifact
+			if a != nil && a.Type == ArtifactPkg.String() && a.AllowUntrusted && !removedPkgs[c.Variant+"\x00"+a.Value] {
+				add(RiskHigh, "pkg '%s' is installed with allow_untrusted: true", a.Value)
+			}
+		case ChangeStanza:
+			switch {
+			case hookStanzas[c.Stanza] && c.Old == "" && c.New != "":
+				add(RiskHigh, "new %s code", c.Stanza)
+			case hookStanzas[c.Stanza] && c.Old != "" && c.New != "":
+				add(RiskMedium, "%s code changed", c.Stanza)
+			case uninstallStanzas[c.Stanza]:
+				for _, directive := range []string{"script", "sudo"} {
+					if countDirective(c.New, directive) > countDirective(c.Old, directive) {
+						add(RiskHigh, "new '%s:' usage in %s", directive, c.Stanza)
+					}
+				}
+			}
+		}
+	}
+
+	return risks
+}
+
+// countDirective returns the number of the provided "directive:" arguments in
+// the normalized stanza source.
+func countDirective(source string, directive string) (count int) {
+	for _, field := range strings.FieldsFunc(source, isDirectiveSeparator) {
+		if field == directive+":" {
+			count++
+		}
+	}
+
+	return count
+}
+
+// isDirectiveSeparator checks whether the provided rune separates the
+// arguments in the normalized stanza source.
+func isDirectiveSeparator(r rune) bool {
+	return strings.ContainsRune(" \n,{}[]()", r)
+}
+
+// String returns the string representation of the RiskSeverity.
+func (s RiskSeverity) String() string {
+	return riskSeverityNames[s]
+}
+
+// MarshalText returns the RiskSeverity name, so it's encoded as a string in
+// the JSON documents.
+func (s RiskSeverity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the RiskSeverity from its name.
+func (s *RiskSeverity) UnmarshalText(text []byte) error {
+	for i, name := range riskSeverityNames {
+		if name == string(text) {
+			*s = RiskSeverity(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown risk severity '%s'", text)
+}
+
+// String returns the string representation of the Risk in the
+// "severity: [variant] reason" format.
+func (r Risk) String() string {
+	if r.Change.Variant != "" {
+		return fmt.Sprintf("%s: [%s] %s", r.Severity, r.Change.Variant, r.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s", r.Severity, r.Reason)
+}
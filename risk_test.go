@@ -0,0 +1,184 @@
+package cask
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyRisks(t *testing.T) {
+	// preparations
+	old, new := parseTestDiffCasks(t, diffTestOld, diffTestNew)
+
+	// test
+	risks := ClassifyRisks(Diff(old, new))
+
+	actual := make([]string, 0, len(risks))
+	for _, r := range risks {
+		actual = append(actual, r.String())
+	}
+
+	assert.Equal(t, []string{
+		"high: [10.13 (all)] sha256 checksum verification is disabled",
+		"high: [10.13 (all)] download host changed from 'example.com' to 'cdn.example.org'",
+		"high: [10.13 (all)] pkg 'app_2.0.0.pkg' is installed with allow_untrusted: true",
+		"high: new preflight code",
+		"high: new 'script:' usage in uninstall",
+		"high: new 'sudo:' usage in uninstall",
+	}, actual)
+	assert.Equal(t, ChangeChecksum, risks[0].Change.Kind)
+
+	// test (reversed)
+	risks = ClassifyRisks(Diff(new, old))
+	assert.Len(t, risks, 2)
+	assert.Equal(t, "low: [10.13 (all)] version downgraded from '2.0.0' to '1.0.0'", risks[0].String())
+	assert.Equal(t, "high: [10.13 (all)] download host changed from 'cdn.example.org' to 'example.com'", risks[1].String())
+}
+
+func TestClassifyRisksAddedVariant(t *testing.T) {
+	// preparations
+	old, new := parseTestDiffCasks(t, diffTestOld, `cask 'example' do
+  if MacOS.version <= :sierra
+    version '1.0.0'
+    sha256 :no_check
+
+    url "https://evil.com/app_#{version}.pkg"
+
+    pkg 'Example.pkg', allow_untrusted: true
+  else
+    version '1.0.0'
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+    url "https://example.com/app_#{version}.dmg"
+
+    app 'Example.app'
+  end
+
+  name 'Example'
+  homepage 'https://example.com/'
+
+  binary 'example'
+
+  uninstall quit: 'com.example.app'
+end
+`)
+
+	// test
+	risks := ClassifyRisks(Diff(old, new))
+
+	actual := make([]string, 0, len(risks))
+	for _, r := range risks {
+		actual = append(actual, r.String())
+	}
+
+	assert.Equal(t, []string{
+		"high: [10.4-10.12 (all)] sha256 checksum verification is disabled",
+		"high: [10.4-10.12 (all)] download host changed from 'example.com' to 'evil.com'",
+		"high: [10.4-10.12 (all)] pkg 'Example.pkg' is installed with allow_untrusted: true",
+	}, actual)
+
+	// test (without the variant used before)
+	old.Variants = nil
+	risks = ClassifyRisks(Diff(old, new))
+	assert.Len(t, risks, 2)
+	assert.Equal(t, "high: [10.4-10.12 (all)] sha256 checksum verification is disabled", risks[0].String())
+	assert.Equal(t, "high: [10.4-10.12 (all)] pkg 'Example.pkg' is installed with allow_untrusted: true", risks[1].String())
+}
+
+func TestClassifyRisksChanges(t *testing.T) {
+	pkg := &ResolvedArtifact{Type: "pkg", Value: "app.pkg", AllowUntrusted: true}
+
+	testCases := map[string][]Change{
+		"medium: [10.13 (all)] sha256 checksum changed without a version change": {
+			{Kind: ChangeChecksum, Variant: "10.13 (all)", Old: "a", New: "b"},
+		},
+		"medium: postflight code changed": {
+			{Kind: ChangeStanza, Stanza: "postflight", Old: "postflight do\nend", New: "postflight do\nx\nend"},
+		},
+		"high: new uninstall_preflight code": {
+			{Kind: ChangeStanza, Stanza: "uninstall_preflight", New: "uninstall_preflight do\nend"},
+		},
+		"high: new 'sudo:' usage in zap": {
+			{Kind: ChangeStanza, Stanza: "zap", Old: "zap script: \"x\"", New: "zap script: \"x\", sudo: true"},
+		},
+		"high: [10.13 (all)] pkg 'app.pkg' is installed with allow_untrusted: true": {
+			{Kind: ChangeArtifactRemoved, Variant: "10.13 (all)", Artifact: &ResolvedArtifact{Type: "pkg", Value: "app.pkg"}},
+			{Kind: ChangeArtifactAdded, Variant: "10.13 (all)", Artifact: pkg},
+		},
+	}
+
+	for expected, changes := range testCases {
+		risks := ClassifyRisks(changes)
+		if assert.Len(t, risks, 1, expected) {
+			assert.Equal(t, expected, risks[0].String())
+		}
+	}
+
+	// test (not risky)
+	testCases = map[string][]Change{
+		"checksum with version": {
+			{Kind: ChangeVersion, Variant: "10.13 (all)", Old: "1.0", New: "1.1"},
+			{Kind: ChangeChecksum, Variant: "10.13 (all)", Old: "a", New: "b"},
+		},
+		"checksum from no_check": {
+			{Kind: ChangeChecksum, Variant: "10.13 (all)", Old: "no_check", New: "a"},
+		},
+		"removed checksum": {
+			{Kind: ChangeChecksum, Variant: "10.13 (all)", Old: "a"},
+		},
+		"url on the same host": {
+			{Kind: ChangeURL, Variant: "10.13 (all)", Old: "https://a.com/1", New: "https://a.com/2"},
+		},
+		"new url": {
+			{Kind: ChangeURLHost, Variant: "10.13 (all)", New: "https://a.com/1"},
+		},
+		"allow_untrusted kept": {
+			{Kind: ChangeArtifactRemoved, Variant: "10.13 (all)", Artifact: pkg},
+			{Kind: ChangeArtifactAdded, Variant: "10.13 (all)", Artifact: pkg},
+		},
+		"removed preflight": {
+			{Kind: ChangeStanza, Stanza: "preflight", Old: "preflight do\nend"},
+		},
+		"uninstall without scripts": {
+			{Kind: ChangeStanza, Stanza: "uninstall", Old: "uninstall script: \"x\"", New: "uninstall script: \"y\""},
+		},
+	}
+
+	for name, changes := range testCases {
+		assert.Empty(t, ClassifyRisks(changes), name)
+	}
+}
+
+func TestRiskSeverityText(t *testing.T) {
+	for i, name := range riskSeverityNames {
+		text, err := RiskSeverity(i).MarshalText()
+		assert.Nil(t, err)
+		assert.Equal(t, name, string(text))
+
+		var severity RiskSeverity
+		assert.Nil(t, severity.UnmarshalText(text))
+		assert.Equal(t, RiskSeverity(i), severity)
+	}
+
+	var severity RiskSeverity
+	assert.EqualError(t, severity.UnmarshalText([]byte("unknown")), "unknown risk severity 'unknown'")
+}
+
+func TestRiskJSON(t *testing.T) {
+	// preparations
+	r := Risk{
+		Change:   Change{Kind: ChangeChecksum, Variant: "10.13 (all)", Stanza: "sha256", Old: "a", New: "no_check"},
+		Severity: RiskHigh,
+		Reason:   "sha256 checksum verification is disabled",
+	}
+
+	// test
+	data, err := json.Marshal(r)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"change": {"kind": "checksum", "variant": "10.13 (all)", "stanza": "sha256", "old": "a", "new": "no_check"},
+		"severity": "high",
+		"reason": "sha256 checksum verification is disabled"
+	}`, string(data))
+}
@@ -0,0 +1,429 @@
+package cask
+
+import (
+	"fmt"
+	"time"
+)
+
+// A Schema represents the stable document schema of the Cask with all variants
+// and their supported macOS releases. It's shared by the JSON, YAML and TOML
+// encodings and all values in it have the interpolations resolved.
+type Schema struct {
+	// Token specifies the cask token.
+	Token string `json:"token" yaml:"token" toml:"token"`
+
+	// Variants specify all cask variants.
+	Variants []VariantSchema `json:"variants" yaml:"variants" toml:"variants"`
+
+	// Deprecated specifies the "deprecate!" stanza. It's nil if the cask isn't
+	// deprecated.
+	Deprecated *LifecycleSchema `json:"deprecated,omitempty" yaml:"deprecated,omitempty" toml:"deprecated,omitempty"`
+
+	// Disabled specifies the "disable!" stanza. It's nil if the cask isn't
+	// disabled.
+	Disabled *LifecycleSchema `json:"disabled,omitempty" yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+}
+
+// A VariantSchema represents the schema of a single Variant. The field names
+// match the Homebrew-Cask JSON API.
+type VariantSchema struct {
+	// MinimumMacOS specifies the minimum supported macOS release symbol. For
+	// example, "sierra".
+	MinimumMacOS string `json:"minimum_macos,omitempty" yaml:"minimum_macos,omitempty" toml:"minimum_macos,omitempty" jsonschema:"macos"`
+
+	// MaximumMacOS specifies the maximum supported macOS release symbol.
+	MaximumMacOS string `json:"maximum_macos,omitempty" yaml:"maximum_macos,omitempty" toml:"maximum_macos,omitempty" jsonschema:"macos"`
+
+	// Arch specifies the supported CPU architecture symbol: "intel" or "arm".
+	// It's empty if all architectures are supported.
+	Arch string `json:"arch,omitempty" yaml:"arch,omitempty" toml:"arch,omitempty" jsonschema:"arch"`
+
+	// Name specifies the application names.
+	Name []string `json:"name" yaml:"name" toml:"name"`
+
+	// Desc specifies the "desc" stanza value if it has been parsed by a custom
+	// StanzaParser.
+	Desc *string `json:"desc" yaml:"desc,omitempty" toml:"desc,omitempty"`
+
+	// Homepage specifies the homepage stanza value.
+	Homepage *string `json:"homepage" yaml:"homepage,omitempty" toml:"homepage,omitempty"`
+
+	// URL specifies the url stanza value.
+	URL *string `json:"url" yaml:"url,omitempty" toml:"url,omitempty"`
+
+	// Appcast specifies the appcast stanza URL.
+	Appcast *string `json:"appcast" yaml:"appcast,omitempty" toml:"appcast,omitempty"`
+
+	// Version specifies the version stanza value.
+	Version *string `json:"version" yaml:"version,omitempty" toml:"version,omitempty"`
+
+	// SHA256 specifies the sha256 stanza value or "no_check" if the checksum
+	// isn't checked. It's nil if the stanza is missing.
+	SHA256 *string `json:"sha256" yaml:"sha256,omitempty" toml:"sha256,omitempty"`
+
+	// Artifacts specify the artifact stanzas.
+	Artifacts ArtifactSchemas `json:"artifacts" yaml:"artifacts" toml:"artifacts"`
+
+	// Livecheck specifies the livecheck stanza.
+	Livecheck *LivecheckSchema `json:"livecheck,omitempty" yaml:"livecheck,omitempty" toml:"livecheck,omitempty"`
+}
+
+// An ArtifactSchema represents the schema of a single Artifact. In JSON, it's
+// encoded in the Homebrew-Cask JSON API shape instead: {"app": ["Example.app",
+// {"target": "Example.app"}]}.
+type ArtifactSchema struct {
+	// Type specifies the artifact stanza name. For example, "app".
+	Type string `json:"type" yaml:"type" toml:"type"`
+
+	// Value specifies the artifact value.
+	Value string `json:"value" yaml:"value" toml:"value"`
+
+	// Target specifies the "target:" value.
+	Target string `json:"target,omitempty" yaml:"target,omitempty" toml:"target,omitempty"`
+
+	// AllowUntrusted specifies the "allow_untrusted:" value.
+	AllowUntrusted bool `json:"allow_untrusted,omitempty" yaml:"allow_untrusted,omitempty" toml:"allow_untrusted,omitempty"`
+}
+
+// ArtifactSchemas represent the schema of the Variant artifacts. The artifacts
+// of the unsupported types are skipped while decoding JSON.
+type ArtifactSchemas []ArtifactSchema
+
+// A LivecheckSchema represents the schema of the Livecheck.
+type LivecheckSchema struct {
+	// URL specifies the checked URL or the referenced stanza symbol. For
+	// example, ":homepage".
+	URL *string `json:"url" yaml:"url,omitempty" toml:"url,omitempty"`
+
+	// Strategy specifies the strategy symbol name. For example, "page_match".
+	Strategy *string `json:"strategy" yaml:"strategy,omitempty" toml:"strategy,omitempty"`
+
+	// Regex specifies the regular expression source without the delimiters.
+	Regex *string `json:"regex" yaml:"regex,omitempty" toml:"regex,omitempty"`
+
+	// RegexFlags specifies the regular expression flags.
+	RegexFlags string `json:"regex_flags,omitempty" yaml:"regex_flags,omitempty" toml:"regex_flags,omitempty"`
+
+	// StrategyBlock specifies the raw source of the custom strategy block.
+	StrategyBlock *string `json:"strategy_block" yaml:"strategy_block,omitempty" toml:"strategy_block,omitempty"`
+}
+
+// A LifecycleSchema represents the schema of the LifecycleEvent.
+type LifecycleSchema struct {
+	// Date specifies the date in the "2006-01-02" layout.
+	Date *string `json:"date" yaml:"date,omitempty" toml:"date,omitempty"`
+
+	// Reason specifies the reason symbol name or the free-form reason.
+	Reason *string `json:"reason" yaml:"reason,omitempty" toml:"reason,omitempty"`
+}
+
+// NewSchema creates a new Schema from the provided Cask and returns its
+// pointer. The "#{arch}" interpolations of the architecture-specific variants
+// are resolved using their Variant.Arch.
+func NewSchema(c *Cask) *Schema {
+	s := &Schema{
+		Token:      c.Token,
+		Variants:   make([]VariantSchema, len(c.Variants)),
+		Deprecated: newLifecycleSchema(c.Lifecycle.Deprecated),
+		Disabled:   newLifecycleSchema(c.Lifecycle.Disabled),
+	}
+
+	for i, v := range c.Variants {
+		s.Variants[i] = NewVariantSchema(v.resolved(v.Arch))
+	}
+
+	return s
+}
+
+// Cask returns the Cask represented by the Schema. Returns an error if any of
+// the values can't be represented, like an unknown macOS release.
+func (s Schema) Cask() (*Cask, error) {
+	c := new(Cask)
+	c.Token = s.Token
+
+	for i, vs := range s.Variants {
+		v, err := vs.Variant()
+		if err != nil {
+			return nil, fmt.Errorf("variant %d: %s", i, err)
+		}
+
+		c.AddVariant(v)
+	}
+
+	var err error
+	if s.Deprecated != nil {
+		if c.Lifecycle.Deprecated, err = s.Deprecated.event(); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Disabled != nil {
+		if c.Lifecycle.Disabled, err = s.Disabled.event(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// NewVariantSchema creates a new VariantSchema from the provided Variant.
+func NewVariantSchema(v *Variant) VariantSchema {
+	s := VariantSchema{
+		MinimumMacOS: v.MinimumSupportedMacOS.Symbol(),
+		MaximumMacOS: v.MaximumSupportedMacOS.Symbol(),
+		Name:         make([]string, 0, len(v.Names)),
+		Artifacts:    make(ArtifactSchemas, 0, len(v.Artifacts)),
+	}
+
+	if v.Arch != ArchAll {
+		s.Arch = v.Arch.Symbol()
+	}
+
+	for _, n := range v.GetNames() {
+		s.Name = append(s.Name, n.Value)
+	}
+
+	if desc := v.GetStanzas("desc"); len(desc) > 0 {
+		value := v.interpolate(desc[0].String())
+		s.Desc = &value
+	}
+
+	if v.Homepage != nil {
+		value := v.GetHomepage().Value
+		s.Homepage = &value
+	}
+
+	if v.URL != nil {
+		value := v.GetURL().Value
+		s.URL = &value
+	}
+
+	if v.Appcast != nil {
+		value := v.GetAppcast().URL
+		s.Appcast = &value
+	}
+
+	if v.Version != nil {
+		value := v.GetVersion().Value
+		s.Version = &value
+	}
+
+	if v.SHA256 != nil {
+		value := v.GetSHA256().Value
+		s.SHA256 = &value
+	}
+
+	for _, a := range v.GetArtifacts() {
+		s.Artifacts = append(s.Artifacts, newArtifactSchema(a))
+	}
+
+	if v.Livecheck != nil {
+		livecheck := newLivecheckSchema(v.GetLivecheck())
+		s.Livecheck = &livecheck
+	}
+
+	return s
+}
+
+// Variant returns the Variant represented by the VariantSchema. The empty macOS
+// releases are left as the zero MacOS value and the VariantSchema.Desc is
+// ignored, as it requires a custom StanzaParser. Returns an error if any of the
+// values can't be represented, like an unknown artifact type.
+func (s VariantSchema) Variant() (*Variant, error) {
+	v := NewVariant()
+
+	for _, m := range []struct {
+		symbol string
+		field  *MacOS
+	}{
+		{s.MinimumMacOS, &v.MinimumSupportedMacOS},
+		{s.MaximumMacOS, &v.MaximumSupportedMacOS},
+	} {
+		if m.symbol == "" {
+			continue
+		}
+
+		mac, ok := macOSFromSymbol(m.symbol)
+		if !ok {
+			return nil, fmt.Errorf("unknown macOS release '%s'", m.symbol)
+		}
+		*m.field = mac
+	}
+
+	if s.Arch != "" {
+		arch, ok := archFromSymbol(s.Arch)
+		if !ok || arch == ArchAll {
+			return nil, fmt.Errorf("unknown architecture '%s'", s.Arch)
+		}
+		v.Arch = arch
+	}
+
+	for _, n := range s.Name {
+		v.AddName(NewName(n))
+	}
+
+	if s.Homepage != nil {
+		v.Homepage = NewHomepage(*s.Homepage)
+	}
+
+	if s.URL != nil {
+		v.URL = NewURL(*s.URL)
+	}
+
+	if s.Appcast != nil {
+		v.Appcast = NewAppcast(*s.Appcast, "")
+	}
+
+	if s.Version != nil {
+		v.Version = NewVersion(*s.Version)
+	}
+
+	if s.SHA256 != nil {
+		v.SHA256 = NewSHA256(*s.SHA256)
+	}
+
+	for _, as := range s.Artifacts {
+		a, err := as.artifact()
+		if err != nil {
+			return nil, err
+		}
+
+		v.AddArtifact(a)
+	}
+
+	if s.Livecheck != nil {
+		v.Livecheck = s.Livecheck.livecheck()
+	}
+
+	return v, nil
+}
+
+// newArtifactSchema creates a new ArtifactSchema from the provided Artifact.
+func newArtifactSchema(a Artifact) ArtifactSchema {
+	return ArtifactSchema{
+		Type:           a.Type.String(),
+		Value:          a.Value,
+		Target:         a.Target,
+		AllowUntrusted: a.AllowUntrusted,
+	}
+}
+
+// artifact returns the Artifact represented by the ArtifactSchema. Returns an
+// error if the artifact type isn't supported.
+func (s ArtifactSchema) artifact() (*Artifact, error) {
+	t, ok := artifactTypeFromName(s.Type)
+	if !ok {
+		return nil, fmt.Errorf("unsupported artifact type '%s'", s.Type)
+	}
+
+	a := NewArtifact(t, s.Value)
+	a.Target = s.Target
+	a.AllowUntrusted = s.AllowUntrusted
+
+	return a, nil
+}
+
+// newLivecheckSchema creates a new LivecheckSchema from the provided
+// Livecheck. The missing values are left as nils.
+func newLivecheckSchema(l Livecheck) (s LivecheckSchema) {
+	s.RegexFlags = l.RegexFlags
+
+	if url := l.String(); url != "" {
+		s.URL = &url
+	}
+
+	if strategy := l.strategySymbol(); strategy != "" {
+		s.Strategy = &strategy
+	}
+
+	if l.Regex != "" {
+		s.Regex = &l.Regex
+	}
+
+	if l.HasStrategyBlock {
+		s.StrategyBlock = &l.StrategyBlock
+	}
+
+	return s
+}
+
+// livecheck returns the Livecheck represented by the LivecheckSchema. The
+// unknown strategy is kept as Livecheck.UnknownStrategy.
+func (s LivecheckSchema) livecheck() *Livecheck {
+	l := NewLivecheck("")
+	l.RegexFlags = s.RegexFlags
+
+	if s.URL != nil {
+		if len(*s.URL) > 0 && (*s.URL)[0] == ':' {
+			l.URLReference = (*s.URL)[1:]
+		} else {
+			l.URL = *s.URL
+		}
+	}
+
+	if s.Strategy != nil {
+		strategy, ok := livecheckStrategyFromSymbol(*s.Strategy)
+		if !ok {
+			l.UnknownStrategy = *s.Strategy
+		}
+		l.Strategy = strategy
+	}
+
+	if s.Regex != nil {
+		l.Regex = *s.Regex
+	}
+
+	if s.StrategyBlock != nil {
+		l.HasStrategyBlock = true
+		l.StrategyBlock = *s.StrategyBlock
+	}
+
+	return l
+}
+
+// newLifecycleSchema creates a new LifecycleSchema from the provided
+// LifecycleEvent and returns its pointer. Returns nil for the nil
+// LifecycleEvent.
+func newLifecycleSchema(e *LifecycleEvent) *LifecycleSchema {
+	if e == nil {
+		return nil
+	}
+
+	s := new(LifecycleSchema)
+
+	if !e.Date.IsZero() {
+		date := e.Date.Format(lifecycleDateLayout)
+		s.Date = &date
+	}
+
+	if reason := e.String(); reason != "" {
+		s.Reason = &reason
+	}
+
+	return s
+}
+
+// event returns the LifecycleEvent represented by the LifecycleSchema. The
+// reasons matching the known LifecycleReason symbols are stored as the
+// LifecycleEvent.Reason. Returns an error if the date can't be parsed.
+func (s LifecycleSchema) event() (*LifecycleEvent, error) {
+	e := NewLifecycleEvent(time.Time{}, ReasonCustom)
+
+	if s.Date != nil {
+		date, err := time.Parse(lifecycleDateLayout, *s.Date)
+		if err != nil {
+			return nil, fmt.Errorf("lifecycle date '%s' is invalid", *s.Date)
+		}
+		e.Date = date
+	}
+
+	if s.Reason != nil {
+		if r, ok := lifecycleReasonFromSymbol(*s.Reason); ok {
+			e.Reason = r
+		} else {
+			e.Message = *s.Reason
+		}
+	}
+
+	return e, nil
+}
@@ -0,0 +1,101 @@
+package cask
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSchema(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("if-two-versions-one-global-appcast.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	s := NewSchema(c)
+	assert.Equal(t, "if-two-versions-one-global-appcast", s.Token)
+	assert.Nil(t, s.Deprecated)
+	assert.Len(t, s.Variants, 2)
+	assert.Equal(t, "mavericks", s.Variants[0].MinimumMacOS)
+	assert.Equal(t, "mavericks", s.Variants[0].MaximumMacOS)
+	assert.Equal(t, "1.0.0", *s.Variants[0].Version)
+	assert.Equal(t, "https://example.com/sparkle/1/appcast.xml", *s.Variants[0].Appcast)
+	assert.Equal(t, "high_sierra", s.Variants[1].MinimumMacOS)
+	assert.Equal(t, "2.0.0", *s.Variants[1].Version)
+	assert.Equal(t, ArtifactSchema{
+		Type:   "binary",
+		Value:  "/Applications/Example.app/Contents/MacOS/example-if",
+		Target: "example",
+	}, s.Variants[1].Artifacts[1])
+
+	data, err := json.Marshal(s)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"minimum_macos":"mavericks"`)
+	assert.Contains(t, string(data), `{"binary":["/Applications/Example.app/Contents/MacOS/example-if",{"target":"example"}]}`)
+
+	// test (lifecycle)
+	c = NewCask(string(getTestdata("deprecated.rb")))
+	assert.Nil(t, c.Parse())
+
+	s = NewSchema(c)
+	assert.Equal(t, "2023-01-01", *s.Deprecated.Date)
+	assert.Equal(t, "discontinued", *s.Deprecated.Reason)
+	assert.Equal(t, "is no longer maintained", *s.Disabled.Reason)
+}
+
+func TestSchemaCask(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("livecheck.rb")))
+	assert.Nil(t, c.Parse())
+
+	// test
+	actual, err := NewSchema(c).Cask()
+	assert.Nil(t, err)
+	assert.Equal(t, NewSchema(c), NewSchema(actual))
+	assert.Equal(t, c.Variants[0].Livecheck.Arguments(), actual.Variants[0].Livecheck.Arguments())
+
+	// test (unknown strategy)
+	strategy := "unknown"
+	actual, err = Schema{Variants: []VariantSchema{{Livecheck: &LivecheckSchema{Strategy: &strategy}}}}.Cask()
+	assert.Nil(t, err)
+	assert.Equal(t, StrategyDefault, actual.Variants[0].Livecheck.Strategy)
+	assert.Equal(t, "unknown", actual.Variants[0].Livecheck.UnknownStrategy)
+
+	// test (errors)
+	date := "2023"
+
+	testCases := map[string]Schema{
+		"variant 0: unknown macOS release 'unknown'": {
+			Variants: []VariantSchema{{MaximumMacOS: "unknown"}},
+		},
+		"variant 0: unknown architecture 'powerpc'": {
+			Variants: []VariantSchema{{Arch: "powerpc"}},
+		},
+		"variant 0: unsupported artifact type 'zap'": {
+			Variants: []VariantSchema{{Artifacts: ArtifactSchemas{{Type: "zap"}}}},
+		},
+		"lifecycle date '2023' is invalid": {
+			Disabled: &LifecycleSchema{Date: &date},
+		},
+	}
+
+	for expected, s := range testCases {
+		_, err := s.Cask()
+		assert.EqualError(t, err, expected)
+	}
+}
+
+func TestLifecycleSchema(t *testing.T) {
+	// preparations
+	date := "2023-01-01"
+	reason := "unsigned"
+
+	// test
+	e, err := LifecycleSchema{&date, &reason}.event()
+	assert.Nil(t, err)
+	assert.Equal(t, NewLifecycleEvent(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), ReasonUnsigned), e)
+	assert.Equal(t, &LifecycleSchema{&date, &reason}, newLifecycleSchema(e))
+	assert.Nil(t, newLifecycleSchema(nil))
+}
@@ -15,6 +15,27 @@ type BaseStanza struct {
 	IsGlobal bool
 }
 
+// A globalStanza represents the stanza that can be marked as global. All
+// stanzas embedding the BaseStanza implement it.
+type globalStanza interface {
+	isGlobal() bool
+	setGlobal(global bool)
+}
+
+// isGlobal returns the BaseStanza.IsGlobal value.
+func (b *BaseStanza) isGlobal() bool {
+	return b.IsGlobal
+}
+
+// setGlobal sets the BaseStanza.IsGlobal value.
+func (b *BaseStanza) setGlobal(global bool) {
+	b.IsGlobal = global
+}
+
+// sha256NoCheck specifies the SHA256.Value of the "sha256 :no_check" stanza,
+// which disables the checksum verification.
+const sha256NoCheck = "no_check"
+
 // A SHA256 represents a sha256 cask stanza.
 type SHA256 struct {
 	BaseStanza
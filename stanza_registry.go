@@ -0,0 +1,169 @@
+package cask
+
+import (
+	"sort"
+	"sync"
+)
+
+// A StanzaParser represents the function that parses a single stanza. When
+// called, the Parser.CurrentToken is the stanza name identifier. The function
+// should move the Parser through all the stanza tokens and return the parsed
+// Stanza or an error if the stanza can't be parsed. The errors are reported
+// the same way as the unknown stanzas, depending on the Parser mode.
+//
+// The returned stanzas embedding the BaseStanza are marked as global
+// automatically if they weren't found inside the if statement.
+type StanzaParser func(p *Parser) (Stanza, error)
+
+// A StanzaRegistry represents the registry of stanza parsers used by the
+// Parser to recognise the stanzas. It's safe for concurrent use.
+type StanzaRegistry struct {
+	// mutex specifies the mutex that guards parsers.
+	mutex sync.RWMutex
+
+	// parsers specify the registered StanzaParser functions by the stanza name.
+	parsers map[string]StanzaParser
+}
+
+// DefaultStanzaRegistry specifies the StanzaRegistry that is used by the Parser
+// unless the WithStanzaRegistry option is passed.
+var DefaultStanzaRegistry = NewStanzaRegistry()
+
+// NewStanzaRegistry creates a new StanzaRegistry instance with all built-in
+// stanzas registered and returns its pointer.
+func NewStanzaRegistry() *StanzaRegistry {
+	r := &StanzaRegistry{
+		parsers: make(map[string]StanzaParser),
+	}
+
+	r.Register("version", func(p *Parser) (Stanza, error) {
+		v, err := p.parseVersion()
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+
+	r.Register("sha256", func(p *Parser) (Stanza, error) {
+		s, err := p.parseSHA256()
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	})
+
+	r.Register("url", func(p *Parser) (Stanza, error) {
+		u, err := p.parseURL()
+		if err != nil {
+			return nil, err
+		}
+		return u, nil
+	})
+
+	r.Register("appcast", func(p *Parser) (Stanza, error) {
+		a, err := p.parseAppcast()
+		if err != nil {
+			return nil, err
+		}
+		return a, nil
+	})
+
+	r.Register("livecheck", func(p *Parser) (Stanza, error) {
+		l, err := p.parseLivecheck()
+		if err != nil {
+			return nil, err
+		}
+		return l, nil
+	})
+
+	r.Register("name", func(p *Parser) (Stanza, error) {
+		n, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	})
+
+	r.Register("homepage", func(p *Parser) (Stanza, error) {
+		h, err := p.parseHomepage()
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	})
+
+	for _, name := range []string{"deprecate!", "disable!"} {
+		r.Register(name, func(p *Parser) (Stanza, error) {
+			e, err := p.parseLifecycleEvent()
+			if err != nil {
+				return nil, err
+			}
+			return e, nil
+		})
+	}
+
+	for _, name := range artifactTypeNames {
+		r.Register(name, func(p *Parser) (Stanza, error) {
+			a, err := p.ParseArtifact()
+			if err != nil {
+				return nil, err
+			}
+			return a, nil
+		})
+	}
+
+	return r
+}
+
+// RegisterStanza registers the StanzaParser for the specified stanza name in
+// the DefaultStanzaRegistry. If the stanza is already registered, its parser
+// is replaced.
+func RegisterStanza(name string, parser StanzaParser) {
+	DefaultStanzaRegistry.Register(name, parser)
+}
+
+// Register registers the StanzaParser for the specified stanza name. If the
+// stanza is already registered, its parser is replaced.
+func (r *StanzaRegistry) Register(name string, parser StanzaParser) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.parsers[name] = parser
+}
+
+// Unregister removes the StanzaParser for the specified stanza name.
+func (r *StanzaRegistry) Unregister(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.parsers, name)
+}
+
+// Lookup returns the StanzaParser for the specified stanza name. The second
+// value reports whether the stanza is registered.
+func (r *StanzaRegistry) Lookup(name string) (StanzaParser, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	parser, ok := r.parsers[name]
+	return parser, ok
+}
+
+// Has checks whether the stanza with the specified name is registered.
+func (r *StanzaRegistry) Has(name string) bool {
+	_, ok := r.Lookup(name)
+	return ok
+}
+
+// Names returns all registered stanza names sorted alphabetically.
+func (r *StanzaRegistry) Names() (names []string) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for name := range r.parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,159 @@
+package cask
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A testAutoUpdates represents the custom "auto_updates" stanza used in tests.
+type testAutoUpdates struct {
+	BaseStanza
+
+	// Value specifies the stanza value.
+	Value bool
+}
+
+// String returns a string representation of the testAutoUpdates struct.
+func (a testAutoUpdates) String() string {
+	if a.Value {
+		return "true"
+	}
+	return "false"
+}
+
+// parseTestAutoUpdates parses the custom "auto_updates" stanza.
+func parseTestAutoUpdates(p *Parser) (Stanza, error) {
+	switch p.PeekToken().Type {
+	case TRUE:
+		p.Accept(TRUE)
+		return &testAutoUpdates{Value: true}, nil
+	case FALSE:
+		p.Accept(FALSE)
+		return &testAutoUpdates{Value: false}, nil
+	}
+
+	return nil, errors.New("auto_updates not found")
+}
+
+func TestNewStanzaRegistry(t *testing.T) {
+	// preparations
+	r := NewStanzaRegistry()
+
+	// test
+	assert.IsType(t, &StanzaRegistry{}, r)
+	assert.Equal(t, []string{
+		"app",
+		"appcast",
+		"binary",
+		"deprecate!",
+		"disable!",
+		"homepage",
+		"livecheck",
+		"name",
+		"pkg",
+		"sha256",
+		"url",
+		"version",
+	}, r.Names())
+}
+
+func TestStanzaRegistryRegister(t *testing.T) {
+	// preparations
+	r := NewStanzaRegistry()
+
+	// test
+	assert.False(t, r.Has("auto_updates"))
+	r.Register("auto_updates", parseTestAutoUpdates)
+	assert.True(t, r.Has("auto_updates"))
+
+	parser, ok := r.Lookup("auto_updates")
+	assert.True(t, ok)
+	assert.NotNil(t, parser)
+
+	r.Unregister("auto_updates")
+	assert.False(t, r.Has("auto_updates"))
+
+	parser, ok = r.Lookup("auto_updates")
+	assert.False(t, ok)
+	assert.Nil(t, parser)
+}
+
+func TestRegisterStanza(t *testing.T) {
+	// preparations
+	defer DefaultStanzaRegistry.Unregister("auto_updates")
+
+	// test
+	assert.False(t, DefaultStanzaRegistry.Has("auto_updates"))
+	RegisterStanza("auto_updates", parseTestAutoUpdates)
+	assert.True(t, DefaultStanzaRegistry.Has("auto_updates"))
+
+	c := NewCask(string(getTestdata("example-one.rb")))
+	assert.Nil(t, c.Parse())
+	assert.Len(t, c.Warnings, 0)
+}
+
+func TestParseWithStanzaRegistry(t *testing.T) {
+	// preparations
+	r := NewStanzaRegistry()
+	r.Register("auto_updates", parseTestAutoUpdates)
+
+	// test (custom stanza)
+	c := NewCask(string(getTestdata("example-one.rb")), WithStanzaRegistry(r), WithStrictMode())
+	assert.Nil(t, c.Parse())
+	assert.Len(t, c.Variants, 1)
+
+	stanzas := c.Variants[0].GetStanzas("auto_updates")
+	assert.Len(t, stanzas, 1)
+	assert.IsType(t, &testAutoUpdates{}, stanzas[0])
+	assert.True(t, stanzas[0].(*testAutoUpdates).Value)
+	assert.True(t, stanzas[0].(*testAutoUpdates).IsGlobal)
+
+	// test (global custom stanza is inherited by all variants)
+	c = NewCask(`cask 'example' do
+  if MacOS.version <= :sierra
+    version '1.0.0'
+  else
+    version '2.0.0'
+  end
+
+  auto_updates true
+end
+`, WithStanzaRegistry(r))
+	assert.Nil(t, c.Parse())
+	assert.Len(t, c.Variants, 2)
+	for _, v := range c.Variants {
+		assert.Len(t, v.GetStanzas("auto_updates"), 1)
+	}
+
+	// test (unregistered built-in stanza)
+	r.Unregister("appcast")
+	c = NewCask(string(getTestdata("example-one.rb")), WithStanzaRegistry(r))
+	assert.Nil(t, c.Parse())
+	assert.Nil(t, c.Variants[0].Appcast)
+	assert.Len(t, c.Warnings, 1)
+	assert.Equal(t, "6:3: unknown stanza 'appcast'", c.Warnings[0].Error())
+}
+
+func TestParseWithStanzaParserError(t *testing.T) {
+	// preparations
+	r := NewStanzaRegistry()
+	r.Register("auto_updates", parseTestAutoUpdates)
+
+	content := "cask 'example' do\n  version '1.0.0'\n  auto_updates 'yes'\nend\n"
+
+	// test (lenient)
+	c := NewCask(content, WithStanzaRegistry(r))
+	assert.Nil(t, c.Parse())
+	assert.Len(t, c.Variants, 1)
+	assert.Empty(t, c.Variants[0].GetStanzas("auto_updates"))
+	assert.Len(t, c.Warnings, 1)
+	assert.Equal(t, "3:3: could not parse 'auto_updates' stanza: auto_updates not found", c.Warnings[0].Error())
+
+	// test (strict)
+	c = NewCask(content, WithStanzaRegistry(r), WithStrictMode())
+	err := c.Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "3:3: could not parse 'auto_updates' stanza: auto_updates not found")
+}
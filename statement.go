@@ -0,0 +1,127 @@
+package cask
+
+// A statementTracker tracks the statement starts and the currently opened
+// blocks in the emitted Lexer tokens. It's used by both the Parser and the
+// SyntaxTree to find the stanzas.
+type statementTracker struct {
+	// previous specifies the previous tracked token.
+	previous Token
+
+	// current specifies the current tracked token.
+	current Token
+
+	// lineContinued specifies if the last NEWLINE token continues the previous
+	// line (for example, it follows a comma), so the next token doesn't start a
+	// new statement.
+	lineContinued bool
+
+	// blocks specify the stack of currently opened blocks represented by their
+	// opening token types (DO or IF). All non-DO blocks are tracked as IF, as
+	// well as the "on_intel do" and "on_arm do" blocks.
+	blocks []TokenType
+}
+
+// track updates the statementTracker state with the next token.
+func (s *statementTracker) track(t Token) {
+	s.previous = s.current
+	s.current = t
+
+	switch t.Type {
+	case DO:
+		if s.previous.Type == IDENT && isArchBlock(s.previous.Literal) {
+			s.blocks = append(s.blocks, IF)
+		} else {
+			s.blocks = append(s.blocks, DO)
+		}
+	case IF:
+		// modifiers like "x if y" don't open a new block
+		if s.isStatementStart() {
+			s.blocks = append(s.blocks, IF)
+		}
+	case IDENT:
+		// other Ruby expressions that are closed by the "end" keyword
+		if s.isStatementStart() && isBlockExpression(t.Literal) {
+			s.blocks = append(s.blocks, IF)
+		}
+	case END:
+		if len(s.blocks) > 0 {
+			s.blocks = s.blocks[:len(s.blocks)-1]
+		}
+	case NEWLINE:
+		switch s.previous.Type {
+		case COMMA, LPAREN, LBRACKET, LBRACE, PIPE:
+			s.lineContinued = true
+		default:
+			s.lineContinued = false
+		}
+	}
+}
+
+// isStatementStart checks whether the current token starts a new statement.
+func (s *statementTracker) isStatementStart() bool {
+	switch s.previous.Type {
+	case NEWLINE:
+		return !s.lineContinued
+	case EOF, SEMICOLON, DO, THEN, ELSE:
+		return true
+	}
+
+	return false
+}
+
+// isStatementEnd checks whether the current token ends the statement, which
+// started when the specified number of blocks were opened.
+func (s *statementTracker) isStatementEnd(depth int) bool {
+	switch {
+	case len(s.blocks) < depth:
+		return true
+	case len(s.blocks) > depth:
+		return false
+	}
+
+	switch s.current.Type {
+	case NEWLINE:
+		return !s.lineContinued
+	case EOF, SEMICOLON:
+		return true
+	}
+
+	return false
+}
+
+// isCaskStatementStart checks whether the current token starts a new
+// statement directly inside the cask block. The statements inside the if
+// blocks are considered to be inside the cask block as well, unlike the ones in
+// other nested blocks (for example, "postflight do").
+func (s *statementTracker) isCaskStatementStart() bool {
+	if !s.isStatementStart() {
+		return false
+	}
+
+	blocks := 0
+	for _, t := range s.blocks {
+		if t == DO {
+			blocks++
+		}
+	}
+
+	return blocks == 1 && s.blocks[0] == DO
+}
+
+// isArchBlock checks whether the specified identifier starts the "on_intel" or
+// "on_arm" block, which stanzas are considered to be inside the cask block.
+func isArchBlock(ident string) bool {
+	_, ok := archFromBlock(ident)
+	return ok
+}
+
+// isBlockExpression checks whether the specified identifier starts a Ruby
+// expression closed by the "end" keyword.
+func isBlockExpression(ident string) bool {
+	switch ident {
+	case "begin", "case", "unless", "until", "while":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,286 @@
+package cask
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A SyntaxToken represents a single Lexer token in the SyntaxTree together
+// with the source text preceding it.
+type SyntaxToken struct {
+	Token
+
+	// Leading specifies the source text between the previous token and this
+	// one: whitespaces, comments and string delimiters.
+	Leading string
+}
+
+// A StanzaNode represents a single stanza statement in the SyntaxTree.
+type StanzaNode struct {
+	// Name specifies the stanza name.
+	Name string
+
+	// Tokens specify all statement tokens starting from the stanza name. The
+	// token ending the statement is not included.
+	Tokens []*SyntaxToken
+
+	// Block specifies the identifier of the innermost block the stanza belongs
+	// to. Each if statement branch is a separate block, so only the stanzas
+	// with the same Block are siblings.
+	Block int
+
+	// tree specifies the SyntaxTree the stanza belongs to.
+	tree *SyntaxTree
+
+	// index specifies the SyntaxTree.Tokens index of the stanza name token.
+	index int
+}
+
+// A SyntaxTree represents the lossless concrete syntax tree of the cask. It
+// keeps all whitespaces and comments, so the cask can be edited by patching
+// only the affected tokens while leaving the rest of the source byte-for-byte
+// identical.
+//
+// The Token.Position values are not updated after the changes.
+type SyntaxTree struct {
+	// Tokens specify all tokens in the order of their appearance.
+	Tokens []*SyntaxToken
+
+	// Stanzas specify all stanza statements inside the cask block including the
+	// ones inside the if and "on_intel"/"on_arm" blocks.
+	Stanzas []*StanzaNode
+
+	// Trailing specifies the source text after the last token.
+	Trailing string
+}
+
+// NewSyntaxTree creates a new SyntaxTree instance from the provided cask
+// content and returns its pointer. Returns an error if the content can't be
+// tokenized.
+func NewSyntaxTree(content string) (*SyntaxTree, error) {
+	t := new(SyntaxTree)
+	l := NewLexer(content)
+
+	var tracker statementTracker
+	var stanza *StanzaNode
+	var blocks []int
+	depth := 0
+	end := 0
+	lastBlock := 0
+
+	for {
+		token := l.NextToken()
+		if token.Type == ILLEGAL {
+			return nil, errors.New(token.Literal)
+		}
+
+		if token.Type == EOF {
+			t.Trailing = content[end:]
+			break
+		}
+
+		st := &SyntaxToken{Token: token, Leading: content[end:token.Position]}
+		t.Tokens = append(t.Tokens, st)
+		end = token.Position + len(token.Literal)
+
+		tracker.track(token)
+
+		// each opened block and each if statement branch gets a new identifier
+		switch {
+		case len(tracker.blocks) > len(blocks):
+			lastBlock++
+			blocks = append(blocks, lastBlock)
+		case len(tracker.blocks) < len(blocks):
+			blocks = blocks[:len(tracker.blocks)]
+		case len(blocks) > 0 && (token.Type == ELSE || token.Type == ELSEIF):
+			lastBlock++
+			blocks[len(blocks)-1] = lastBlock
+		}
+
+		if stanza != nil && tracker.isStatementEnd(depth) {
+			stanza = nil
+		}
+
+		if stanza == nil && token.Type == IDENT && tracker.isCaskStatementStart() && !isBlockExpression(token.Literal) && !isArchBlock(token.Literal) {
+			stanza = &StanzaNode{
+				Name:  token.Literal,
+				Block: blocks[len(blocks)-1],
+				tree:  t,
+				index: len(t.Tokens) - 1,
+			}
+			depth = len(tracker.blocks)
+			t.Stanzas = append(t.Stanzas, stanza)
+		}
+
+		if stanza != nil {
+			stanza.Tokens = append(stanza.Tokens, st)
+		}
+	}
+
+	return t, nil
+}
+
+// String returns the cask source represented by the SyntaxTree including all
+// changes.
+func (t *SyntaxTree) String() string {
+	var buf bytes.Buffer
+
+	for _, token := range t.Tokens {
+		buf.WriteString(token.Leading)
+		buf.WriteString(token.Literal)
+	}
+
+	buf.WriteString(t.Trailing)
+
+	return buf.String()
+}
+
+// FindStanzas returns all stanzas with the specified name.
+func (t *SyntaxTree) FindStanzas(name string) (stanzas []*StanzaNode) {
+	for _, s := range t.Stanzas {
+		if s.Name == name {
+			stanzas = append(stanzas, s)
+		}
+	}
+
+	return stanzas
+}
+
+// SetVersion sets the value of the version stanza. Returns an error if the
+// cask doesn't have exactly one version stanza. In the casks with multiple
+// variants, the StanzaNode.SetValue should be used instead.
+func (t *SyntaxTree) SetVersion(version string) error {
+	return t.setSingleStanzaValue("version", version)
+}
+
+// SetSHA256 sets the value of the sha256 stanza. Returns an error if the cask
+// doesn't have exactly one sha256 stanza. In the casks with multiple variants,
+// the StanzaNode.SetValue should be used instead.
+func (t *SyntaxTree) SetSHA256(sha256 string) error {
+	return t.setSingleStanzaValue("sha256", sha256)
+}
+
+// ReplaceURL replaces the value of all url stanzas matching the old value with
+// the new one. Returns an error if there are no such url stanzas.
+func (t *SyntaxTree) ReplaceURL(oldURL string, newURL string) error {
+	found := false
+
+	for _, s := range t.FindStanzas("url") {
+		if s.Value() != oldURL {
+			continue
+		}
+
+		if err := s.SetValue(newURL); err != nil {
+			return err
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("url '%s' not found", oldURL)
+	}
+
+	return nil
+}
+
+// setSingleStanzaValue sets the value of the only stanza with the specified
+// name.
+func (t *SyntaxTree) setSingleStanzaValue(name string, value string) error {
+	stanzas := t.FindStanzas(name)
+	if len(stanzas) != 1 {
+		return fmt.Errorf("expected a single '%s' stanza, found %d", name, len(stanzas))
+	}
+
+	return stanzas[0].SetValue(value)
+}
+
+// Value returns the stanza value, which is the first string or symbol
+// argument. Returns an empty string if there is no such argument.
+func (s *StanzaNode) Value() string {
+	if t := s.ValueToken(); t != nil {
+		return t.Literal
+	}
+
+	return ""
+}
+
+// ValueToken returns the stanza value token, which is the first string or
+// symbol argument. Returns nil if there is no such argument.
+func (s *StanzaNode) ValueToken() *SyntaxToken {
+	if i := s.valueIndex(); i > 0 {
+		return s.Tokens[i]
+	}
+
+	return nil
+}
+
+// SetValue replaces the stanza value, which is the first string or symbol
+// argument, with the provided string. The original string delimiters are kept
+// when possible. The symbols (like "version :latest") are replaced by strings.
+// Returns an error if the stanza doesn't have a value that can be replaced.
+func (s *StanzaNode) SetValue(value string) error {
+	i := s.valueIndex()
+	if i < 0 {
+		return fmt.Errorf("%s stanza has no value", s.Name)
+	}
+
+	token := s.Tokens[i]
+
+	// the closing delimiter is a part of the text following the token
+	closing := &s.tree.Trailing
+	if next := s.index + i + 1; next < len(s.tree.Tokens) {
+		closing = &s.tree.Tokens[next].Leading
+	}
+
+	if token.Type == SYMBOL {
+		if !strings.HasSuffix(token.Leading, ":") {
+			return fmt.Errorf("%s stanza value can't be replaced", s.Name)
+		}
+
+		quoted := rubyString(value)
+		token.Leading = strings.TrimSuffix(token.Leading, ":") + quoted[:1]
+		*closing = quoted[:1] + *closing
+		token.Type = STRING
+		token.Literal = quoted[1 : len(quoted)-1]
+
+		return nil
+	}
+
+	if token.Leading == "" || *closing == "" {
+		return fmt.Errorf("%s stanza value can't be replaced", s.Name)
+	}
+
+	left, right := token.Leading[len(token.Leading)-1], (*closing)[0]
+	if left != right || (left != '\'' && left != '"') {
+		return fmt.Errorf("%s stanza value can't be replaced", s.Name)
+	}
+
+	quoted := rubyString(value)
+	if left == '"' {
+		quoted = rubyDoubleQuotedString(value)
+	}
+
+	token.Leading = token.Leading[:len(token.Leading)-1] + quoted[:1]
+	*closing = quoted[:1] + (*closing)[1:]
+	token.Literal = quoted[1 : len(quoted)-1]
+
+	return nil
+}
+
+// valueIndex returns the StanzaNode.Tokens index of the stanza value token,
+// which should be the first stanza argument. Returns -1 if there is no such
+// token.
+func (s *StanzaNode) valueIndex() int {
+	i := 1
+	if i < len(s.Tokens) && s.Tokens[i].Type == LPAREN {
+		i++
+	}
+
+	if i < len(s.Tokens) && (s.Tokens[i].Type == STRING || s.Tokens[i].Type == SYMBOL) {
+		return i
+	}
+
+	return -1
+}
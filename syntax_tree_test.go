@@ -0,0 +1,217 @@
+package cask
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSyntaxTreeContent = `# frozen_string_literal: true
+
+cask 'example' do # the example cask
+  version '1.0.0' # current version
+  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+  # the download url
+  url "https://example.com/app_#{version}.dmg",
+      verified: 'example.com/'
+  name 'Example'
+
+  auto_updates true
+
+  app 'Example.app'
+
+  postflight do
+    set_permissions "#{appdir}/Example.app", '0755'
+  end
+end
+`
+
+func TestNewSyntaxTree(t *testing.T) {
+	// preparations
+	tree, err := NewSyntaxTree(testSyntaxTreeContent)
+	assert.Nil(t, err)
+
+	// test
+	assert.Equal(t, testSyntaxTreeContent, tree.String())
+	assert.Empty(t, tree.Trailing)
+	assert.Equal(t, NEWLINE, tree.Tokens[len(tree.Tokens)-1].Type)
+
+	var names []string
+	for _, s := range tree.Stanzas {
+		names = append(names, s.Name)
+	}
+
+	assert.Equal(t, []string{
+		"version",
+		"sha256",
+		"url",
+		"name",
+		"auto_updates",
+		"app",
+		"postflight",
+	}, names)
+
+	assert.Len(t, tree.Stanzas[2].Tokens, 7)
+	assert.Equal(t, "postflight", tree.Stanzas[6].Tokens[0].Literal)
+	assert.Equal(t, END, tree.Stanzas[6].Tokens[len(tree.Stanzas[6].Tokens)-1].Type)
+}
+
+func TestNewSyntaxTreeTestdata(t *testing.T) {
+	files, err := ioutil.ReadDir(filepath.Join(getWorkingDir(), testdataPath))
+	assert.Nil(t, err)
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		// preparations
+		content := string(getTestdata(file.Name()))
+
+		// test
+		tree, err := NewSyntaxTree(content)
+		assert.Nil(t, err, file.Name())
+		assert.Equal(t, content, tree.String(), file.Name())
+	}
+}
+
+func TestNewSyntaxTreeError(t *testing.T) {
+	// test
+	tree, err := NewSyntaxTree("cask 'example' do\n  version '1.0.0\nend\n")
+	assert.Nil(t, tree)
+	assert.EqualError(t, err, "Unterminated string at 28")
+}
+
+func TestSyntaxTreeFindStanzas(t *testing.T) {
+	// preparations
+	tree, err := NewSyntaxTree(string(getTestdata("example-two.rb")))
+	assert.Nil(t, err)
+
+	// test
+	stanzas := tree.FindStanzas("version")
+	assert.Len(t, stanzas, 2)
+	assert.Equal(t, "1.5.0", stanzas[0].Value())
+	assert.Equal(t, "2.0.0", stanzas[1].Value())
+	assert.Equal(t, STRING, stanzas[1].ValueToken().Type)
+	assert.Nil(t, tree.FindStanzas("uninstall")[0].ValueToken())
+	assert.Empty(t, tree.FindStanzas("uninstall")[0].Value())
+
+	assert.NotEqual(t, stanzas[0].Block, stanzas[1].Block)
+	assert.Equal(t, tree.FindStanzas("name")[0].Block, tree.FindStanzas("uninstall")[0].Block)
+	assert.Equal(t, stanzas[0].Block, tree.FindStanzas("sha256")[0].Block)
+
+	assert.Len(t, tree.FindStanzas("uninstall"), 1)
+	assert.Empty(t, tree.FindStanzas("postflight"))
+}
+
+func TestSyntaxTreeEdit(t *testing.T) {
+	// preparations
+	tree, err := NewSyntaxTree(testSyntaxTreeContent)
+	assert.Nil(t, err)
+
+	// test
+	assert.Nil(t, tree.SetVersion("2.0.0"))
+	assert.Nil(t, tree.SetSHA256("9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7"))
+	assert.Nil(t, tree.ReplaceURL(
+		"https://example.com/app_#{version}.dmg",
+		`https://example.com/#{version.major}/app "#{version}".dmg`,
+	))
+
+	assert.Equal(t, `# frozen_string_literal: true
+
+cask 'example' do # the example cask
+  version '2.0.0' # current version
+  sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+
+  # the download url
+  url "https://example.com/#{version.major}/app \"#{version}\".dmg",
+      verified: 'example.com/'
+  name 'Example'
+
+  auto_updates true
+
+  app 'Example.app'
+
+  postflight do
+    set_permissions "#{appdir}/Example.app", '0755'
+  end
+end
+`, tree.String())
+
+	// test (reparse)
+	c := NewCask(tree.String())
+	assert.Nil(t, c.Parse())
+	assert.Equal(t, "2.0.0", c.Variants[0].GetVersion().Value)
+	assert.Equal(t, `https://example.com/2/app \"2.0.0\".dmg`, c.Variants[0].GetURL().Value)
+}
+
+func TestStanzaNodeSetValue(t *testing.T) {
+	testCases := []struct {
+		content  string
+		value    string
+		expected string
+	}{
+		// single quotes
+		{"version '1.0.0'", "2.0.0", "version '2.0.0'"},
+		{"version '1.0.0'", "#{token}", `version "#{token}"`},
+		{"version '1.0.0'", "it's", `version "it's"`},
+
+		// double quotes
+		{`version "1.0.0"`, "2.0.0", `version "2.0.0"`},
+		{`version "1.0.0"`, `say "hi"`, `version "say \"hi\""`},
+
+		// symbol
+		{"version :latest", "2.0.0", "version '2.0.0'"},
+		{"version :latest # comment", "#{token}", `version "#{token}" # comment`},
+	}
+
+	for _, testCase := range testCases {
+		for _, trailing := range []string{"\nend\n", "\nend"} {
+			// preparations
+			tree, err := NewSyntaxTree("cask 'example' do\n  " + testCase.content + trailing)
+			assert.Nil(t, err)
+
+			// test
+			assert.Nil(t, tree.SetVersion(testCase.value), testCase.content)
+			assert.Equal(t, "cask 'example' do\n  "+testCase.expected+trailing, tree.String(), testCase.content)
+		}
+	}
+
+	// test (last token)
+	tree, err := NewSyntaxTree("cask 'example' do\n  version '1.0.0'")
+	assert.Nil(t, err)
+	assert.Equal(t, "'", tree.Trailing)
+	assert.Nil(t, tree.SetVersion("2.0.0"))
+	assert.Equal(t, "cask 'example' do\n  version '2.0.0'", tree.String())
+
+	// test (outside the cask block)
+	tree, err = NewSyntaxTree("version '1.0.0'")
+	assert.Nil(t, err)
+	assert.Empty(t, tree.Stanzas)
+}
+
+func TestSyntaxTreeEditErrors(t *testing.T) {
+	// preparations
+	tree, err := NewSyntaxTree(string(getTestdata("example-two.rb")))
+	assert.Nil(t, err)
+
+	// test
+	assert.EqualError(t, tree.SetVersion("3.0.0"), "expected a single 'version' stanza, found 2")
+	assert.EqualError(t, tree.SetSHA256("3.0.0"), "expected a single 'sha256' stanza, found 2")
+	assert.EqualError(t, tree.ReplaceURL("https://example.com/", "https://example.org/"), "url 'https://example.com/' not found")
+	assert.Equal(t, string(getTestdata("example-two.rb")), tree.String())
+
+	// test (no value)
+	tree, err = NewSyntaxTree(testSyntaxTreeContent)
+	assert.Nil(t, err)
+	assert.EqualError(t, tree.FindStanzas("postflight")[0].SetValue("test"), "postflight stanza has no value")
+	assert.EqualError(t, tree.FindStanzas("auto_updates")[0].SetValue("test"), "auto_updates stanza has no value")
+
+	// test (heredoc)
+	tree, err = NewSyntaxTree("cask 'example' do\n  caveats <<~EOS\n    Example\n  EOS\nend\n")
+	assert.Nil(t, err)
+	assert.EqualError(t, tree.FindStanzas("caveats")[0].SetValue("test"), "caveats stanza has no value")
+}
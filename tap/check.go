@@ -0,0 +1,122 @@
+package tap
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Different finding rule IDs.
+const (
+	RuleTokenMismatch  = "token-mismatch"
+	RuleDuplicateToken = "duplicate-token"
+	RuleTokenSyntax    = "token-syntax"
+)
+
+var (
+	// tokenNameRegexp matches the token name before the optional "@" suffix:
+	// the lowercase letters and digits separated by single hyphens.
+	tokenNameRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+	// tokenSuffixRegexp matches the token "@" suffix, which is usually a
+	// version or a release channel: the lowercase letters and digits separated
+	// by single dots or hyphens.
+	tokenSuffixRegexp = regexp.MustCompile(`^[a-z0-9]+([.-][a-z0-9]+)*$`)
+)
+
+// A Finding represents a single tap consistency problem found in a cask file.
+type Finding struct {
+	// Rule specifies the ID of the rule that reported the finding.
+	Rule string
+
+	// Token specifies the checked cask token.
+	Token string
+
+	// Path specifies the cask file path relative to the Tap.Dir.
+	Path string
+
+	// Message specifies the human-readable finding description.
+	Message string
+}
+
+// String returns the string representation of the Finding in the
+// "path: message (rule)" format.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Path, f.Message, f.Rule)
+}
+
+// ValidateToken checks whether the provided token follows the Homebrew-Cask
+// token rules: only the lowercase letters, digits and single hyphens are
+// allowed with an optional "@" suffix like "@beta" or "@1.2". Returns an error
+// describing the first broken rule.
+func ValidateToken(token string) error {
+	name, suffix := token, ""
+	hasSuffix := false
+	if i := strings.Index(token, "@"); i >= 0 {
+		name, suffix, hasSuffix = token[:i], token[i+1:], true
+	}
+
+	switch {
+	case token == "":
+		return errors.New("token is empty")
+	case strings.ToLower(token) != token:
+		return fmt.Errorf("token '%s' must be lowercase", token)
+	case strings.Count(token, "@") > 1:
+		return fmt.Errorf("token '%s' must have only one '@' suffix", token)
+	case hasSuffix && !tokenSuffixRegexp.MatchString(suffix):
+		return fmt.Errorf("token '%s' has an invalid '@' suffix", token)
+	case strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") || strings.Contains(name, "--"):
+		return fmt.Errorf("token '%s' can't have leading, trailing or consecutive hyphens", token)
+	case !tokenNameRegexp.MatchString(name):
+		return fmt.Errorf("token '%s' can contain only lowercase letters, digits and hyphens", token)
+	}
+
+	return nil
+}
+
+// check returns the findings of the provided results in the same order: the
+// cask tokens not matching the file names, the duplicate tokens and the
+// invalid tokens. The cask token literal is checked when available, otherwise
+// the file name.
+func check(results []*Result) (findings []Finding) {
+	first := make(map[string]*Result)
+
+	for _, r := range results {
+		token := r.Token
+		if r.Cask != nil && r.Cask.Token != "" {
+			token = r.Cask.Token
+
+			if token != r.Token {
+				findings = append(findings, Finding{
+					Rule:    RuleTokenMismatch,
+					Token:   token,
+					Path:    r.Path,
+					Message: fmt.Sprintf("cask token '%s' doesn't match the file name '%s.rb'", token, r.Token),
+				})
+			}
+		}
+
+		if f, ok := first[token]; ok {
+			findings = append(findings, Finding{
+				Rule:    RuleDuplicateToken,
+				Token:   token,
+				Path:    r.Path,
+				Message: fmt.Sprintf("token '%s' is already defined in %s", token, f.Path),
+			})
+		} else {
+			first[token] = r
+		}
+
+		if err := ValidateToken(token); err != nil {
+			findings = append(findings, Finding{
+				Rule:    RuleTokenSyntax,
+				Token:   token,
+				Path:    r.Path,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,85 @@
+package tap
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindingString(t *testing.T) {
+	// preparations
+	f := Finding{
+		Rule:    RuleDuplicateToken,
+		Token:   "foo",
+		Path:    "Casks/f/foo.rb",
+		Message: "token 'foo' is already defined in Casks/a/foo.rb",
+	}
+
+	// test
+	assert.Equal(t, "Casks/f/foo.rb: token 'foo' is already defined in Casks/a/foo.rb (duplicate-token)", f.String())
+}
+
+func TestValidateToken(t *testing.T) {
+	// test (valid)
+	for _, token := range []string{"foo", "foo-bar", "1password", "foo@beta", "foo@1.2", "foo-bar@2024-preview"} {
+		assert.Nil(t, ValidateToken(token), token)
+	}
+
+	// test (invalid)
+	testCases := map[string]string{
+		"":            "token is empty",
+		"Foo":         "token 'Foo' must be lowercase",
+		"foo@bar@baz": "token 'foo@bar@baz' must have only one '@' suffix",
+		"foo@":        "token 'foo@' has an invalid '@' suffix",
+		"foo@1..2":    "token 'foo@1..2' has an invalid '@' suffix",
+		"-foo":        "token '-foo' can't have leading, trailing or consecutive hyphens",
+		"foo-":        "token 'foo-' can't have leading, trailing or consecutive hyphens",
+		"foo--bar":    "token 'foo--bar' can't have leading, trailing or consecutive hyphens",
+		"foo_bar":     "token 'foo_bar' can contain only lowercase letters, digits and hyphens",
+		"foo.bar":     "token 'foo.bar' can contain only lowercase letters, digits and hyphens",
+		"@beta":       "token '@beta' can contain only lowercase letters, digits and hyphens",
+	}
+
+	for token, expected := range testCases {
+		assert.EqualError(t, ValidateToken(token), expected, token)
+	}
+}
+
+func TestLoadTapFindings(t *testing.T) {
+	// preparations
+	dir := filepath.Join("testdata", "inconsistent")
+
+	// test
+	tap, err := LoadTap(dir)
+	assert.Nil(t, err)
+	assert.Len(t, tap.Results, 5)
+	assert.Empty(t, tap.Errors)
+	assert.Equal(t, []Finding{
+		{
+			Rule:    RuleTokenMismatch,
+			Token:   "baz",
+			Path:    "Casks/b/bar.rb",
+			Message: "cask token 'baz' doesn't match the file name 'bar.rb'",
+		},
+		{
+			Rule:    RuleTokenSyntax,
+			Token:   "Foo_Bar",
+			Path:    "Casks/f/Foo_Bar.rb",
+			Message: "token 'Foo_Bar' must be lowercase",
+		},
+		{
+			Rule:    RuleDuplicateToken,
+			Token:   "foo",
+			Path:    "Casks/f/foo.rb",
+			Message: "token 'foo' is already defined in Casks/a/foo.rb",
+		},
+	}, tap.Findings)
+
+	// test (consistent taps)
+	for _, name := range []string{"flat", "sharded"} {
+		tap, err := LoadTap(filepath.Join("testdata", name))
+		assert.Nil(t, err)
+		assert.Empty(t, tap.Findings, name)
+	}
+}
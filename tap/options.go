@@ -0,0 +1,53 @@
+package tap
+
+import (
+	"runtime"
+
+	"github.com/victorpopkov/go-cask"
+)
+
+// An Option represents a function that configures the Options.
+type Option func(*Options)
+
+// Options represents all options that configure the LoadTap.
+type Options struct {
+	// Workers specifies the maximum number of casks parsed concurrently. By
+	// default, it's the number of CPUs.
+	Workers int
+
+	// CaskOptions specify the cask.Option functions passed to each
+	// cask.NewCask.
+	CaskOptions []cask.Option
+}
+
+// NewOptions creates a new Options instance with the specified options
+// applied over the defaults and returns its pointer.
+func NewOptions(opts ...Option) *Options {
+	o := &Options{
+		Workers: runtime.NumCPU(),
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.Workers < 1 {
+		o.Workers = 1
+	}
+
+	return o
+}
+
+// WithWorkers returns an Option that sets the Options.Workers.
+func WithWorkers(workers int) Option {
+	return func(o *Options) {
+		o.Workers = workers
+	}
+}
+
+// WithCaskOptions returns an Option that sets the Options.CaskOptions.
+func WithCaskOptions(opts ...cask.Option) Option {
+	return func(o *Options) {
+		o.CaskOptions = opts
+	}
+}
@@ -0,0 +1,180 @@
+// Package tap loads all casks from a local Homebrew-Cask tap checkout. The
+// casks are parsed concurrently by a bounded pool of workers.
+package tap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/victorpopkov/go-cask"
+)
+
+// casksDir specifies the tap directory with the casks.
+const casksDir = "Casks"
+
+// A Tap represents the loaded tap.
+type Tap struct {
+	// Dir specifies the tap directory.
+	Dir string
+
+	// Results specify the results of all cask files sorted by their paths.
+	Results []*Result
+
+	// Casks specify the successfully parsed casks keyed by their tokens.
+	Casks map[string]*cask.Cask
+
+	// Errors specify the errors of the casks that couldn't be loaded keyed by
+	// their tokens.
+	Errors map[string]error
+
+	// Findings specify the token consistency problems: the cask tokens not
+	// matching the file names, the duplicate tokens across the shards and the
+	// invalid tokens.
+	Findings []Finding
+}
+
+// A Result represents the result of loading a single cask file.
+type Result struct {
+	// Token specifies the cask token, which is the file name without the ".rb"
+	// extension.
+	Token string
+
+	// Path specifies the cask file path relative to the Tap.Dir. For example:
+	// "Casks/a/alfred.rb".
+	Path string
+
+	// Cask specifies the parsed cask. It's nil if the file couldn't be read.
+	Cask *cask.Cask
+
+	// Err specifies the reading or parsing error.
+	Err error
+}
+
+// LoadTap loads all casks from the "Casks" directory of the provided tap
+// directory including the sharded layouts like "Casks/a/alfred.rb". Each cask
+// is parsed by one of the Options.Workers concurrently. The per-file reading
+// and parsing errors are returned in the Tap, so only the errors finding the
+// cask files are returned. The token consistency problems are returned as the
+// Tap.Findings.
+func LoadTap(dir string, opts ...Option) (*Tap, error) {
+	o := NewOptions(opts...)
+
+	paths, err := findCasks(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tap{
+		Dir:     dir,
+		Results: make([]*Result, len(paths)),
+		Casks:   make(map[string]*cask.Cask),
+		Errors:  make(map[string]error),
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < o.Workers && i < len(paths); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				t.Results[i] = loadCask(dir, paths[i], o)
+			}
+		}()
+	}
+
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	for _, r := range t.Results {
+		if _, ok := t.Casks[r.Token]; ok {
+			continue
+		}
+
+		if _, ok := t.Errors[r.Token]; ok {
+			continue
+		}
+
+		if r.Err != nil {
+			t.Errors[r.Token] = r.Err
+			continue
+		}
+
+		t.Casks[r.Token] = r.Cask
+	}
+
+	t.Findings = check(t.Results)
+
+	return t, nil
+}
+
+// findCasks returns the paths of all ".rb" files in the "Casks" directory of
+// the provided tap directory relative to it sorted alphabetically.
+func findCasks(dir string) (paths []string, err error) {
+	root := filepath.Join(dir, casksDir)
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(path) != ".rb" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, filepath.ToSlash(rel))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// loadCask reads and parses the cask file with the provided path relative to
+// the tap directory.
+func loadCask(dir string, path string, o *Options) *Result {
+	r := &Result{
+		Token: strings.TrimSuffix(filepath.Base(path), ".rb"),
+		Path:  path,
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	r.Cask = cask.NewCask(string(content), o.CaskOptions...)
+	r.Err = r.Cask.Parse()
+
+	return r
+}
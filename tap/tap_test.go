@@ -0,0 +1,100 @@
+package tap
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/victorpopkov/go-cask"
+)
+
+// resultPaths returns the paths of the provided results.
+func resultPaths(results []*Result) (paths []string) {
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+
+	return paths
+}
+
+func TestLoadTap(t *testing.T) {
+	// preparations
+	dir := filepath.Join("testdata", "flat")
+
+	// test
+	tap, err := LoadTap(dir, WithWorkers(2))
+	assert.Nil(t, err)
+	assert.Equal(t, dir, tap.Dir)
+	assert.Equal(t, []string{
+		"Casks/broken.rb",
+		"Casks/example-one.rb",
+		"Casks/example-two.rb",
+		"Casks/livecheck.rb",
+	}, resultPaths(tap.Results))
+
+	assert.Len(t, tap.Casks, 3)
+	assert.Equal(t, "example-one", tap.Casks["example-one"].Token)
+	assert.Equal(t, "example-two", tap.Casks["example-two"].Token)
+	assert.Equal(t, "livecheck", tap.Casks["livecheck"].Token)
+
+	assert.Len(t, tap.Errors, 1)
+	assert.Error(t, tap.Errors["broken"])
+	assert.Contains(t, tap.Errors["broken"].Error(), "Unterminated string")
+
+	r := tap.Results[0]
+	assert.Equal(t, "broken", r.Token)
+	assert.NotNil(t, r.Cask)
+	assert.Equal(t, tap.Errors["broken"], r.Err)
+}
+
+func TestLoadTapSharded(t *testing.T) {
+	// preparations
+	dir := filepath.Join("testdata", "sharded")
+
+	// test
+	tap, err := LoadTap(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"Casks/e/example-one.rb",
+		"Casks/e/example-two.rb",
+		"Casks/l/livecheck.rb",
+	}, resultPaths(tap.Results))
+	assert.Len(t, tap.Casks, 3)
+	assert.Empty(t, tap.Errors)
+}
+
+func TestLoadTapCaskOptions(t *testing.T) {
+	// preparations
+	dir := filepath.Join("testdata", "sharded")
+
+	// test
+	tap, err := LoadTap(dir, WithCaskOptions(cask.WithMaxInputSize(10)))
+	assert.Nil(t, err)
+	assert.Empty(t, tap.Casks)
+	assert.Len(t, tap.Errors, 3)
+	assert.Contains(t, tap.Errors["livecheck"].Error(), "exceeds the maximum input size")
+}
+
+func TestLoadTapErrors(t *testing.T) {
+	// test (missing tap)
+	tap, err := LoadTap(filepath.Join("testdata", "missing"))
+	assert.Nil(t, tap)
+	assert.Error(t, err)
+
+	// test (not a directory)
+	tap, err = LoadTap(filepath.Join("testdata", "invalid"))
+	assert.Nil(t, tap)
+	assert.EqualError(t, err, filepath.Join("testdata", "invalid", "Casks")+" is not a directory")
+}
+
+func TestNewOptions(t *testing.T) {
+	// test (defaults)
+	o := NewOptions()
+	assert.True(t, o.Workers >= 1)
+	assert.Nil(t, o.CaskOptions)
+
+	// test
+	o = NewOptions(WithWorkers(0), WithCaskOptions(cask.WithStrictMode()))
+	assert.Equal(t, 1, o.Workers)
+	assert.Len(t, o.CaskOptions, 1)
+}
@@ -0,0 +1,3 @@
+cask 'broken' do
+  version '1.0.0
+end
@@ -0,0 +1,21 @@
+cask 'livecheck' do
+  version '1.0.0'
+  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+  url "https://example.com/app_#{version}.dmg"
+  name 'Example'
+  homepage 'https://example.com/'
+
+  livecheck do
+    url :homepage
+    strategy :page_match do |page|
+      match = page.match(/href=.*?app_(\d+(?:\.\d+)+)\.dmg/i)
+      next if match.blank?
+
+      match[1]
+    end
+    regex(/href=.*?app_(\d+(?:\.\d+)+)\.dmg/i)
+  end
+
+  app 'Example.app'
+end
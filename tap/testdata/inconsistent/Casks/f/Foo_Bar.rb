@@ -0,0 +1,10 @@
+cask 'Foo_Bar' do
+  version '1.0.0'
+  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+  url 'https://example.com/app.dmg'
+  name 'Example'
+  homepage 'https://example.com/'
+
+  app 'Example.app'
+end
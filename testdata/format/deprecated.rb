@@ -0,0 +1,13 @@
+cask 'deprecated' do
+  version '1.0.0'
+  sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+  url "https://example.com/app_#{version}.dmg"
+  name 'Example'
+  homepage 'https://example.com/'
+
+  deprecate! date: '2023-01-01', because: :discontinued
+  disable! date: '2024-01-01', because: 'is no longer maintained'
+
+  app 'Example.app'
+end
@@ -0,0 +1,16 @@
+cask 'if-no-check' do
+  if MacOS.version <= :sierra
+    version '1.0.0'
+    sha256 :no_check
+  else
+    version '2.0.0'
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+  end
+
+  url "https://example.com/app_#{version}.dmg"
+  name 'Example'
+  name 'Example (if-no-check)'
+  homepage 'https://example.com/'
+
+  app 'Example (if-no-check).app', target: 'Example.app'
+end
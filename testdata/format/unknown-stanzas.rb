@@ -0,0 +1,9 @@
+cask 'unknown-stanzas' do
+  version '1.0.0'
+  sha256 '5e1e2bcac305958b27077ca136f35f0abae7cf38c9af678f7d220ed0cb51d4f8'
+
+  url "https://example.com/app_#{version}.dmg"
+  name 'Example'
+
+  app 'Example.app'
+end
@@ -45,6 +45,7 @@ const (
 	SLASH    // /
 	MODULUS  // %
 
+	AND   // &&
 	EQ    // ==
 	GT    // >
 	LT    // <
@@ -4,9 +4,9 @@ package cask
 
 import "fmt"
 
-const _TokenType_name = "EOFILLEGALCONSTGLOBALIDENTINTSTRINGSYMBOLPNREGEXPPNSTARTPNENDHEREDOCHEREDOCSTARTHEREDOCENDASSIGNASTERISKBANGMINUSPLUSSLASHMODULUSEQGTLTNOTEQCOMMANEWLINESEMICOLONCOLONDOTLBRACELBRACKETLPARENPIPERBRACERBRACKETRPARENSCOPEREGEXPCLASSDEFDOELSEELSEIFENDFALSEIFMODULENILRETURNSELFTHENTRUEYIELD"
+const _TokenType_name = "EOFILLEGALCONSTGLOBALIDENTINTSTRINGSYMBOLPNREGEXPPNSTARTPNENDHEREDOCHEREDOCSTARTHEREDOCENDASSIGNASTERISKBANGMINUSPLUSSLASHMODULUSANDEQGTLTNOTEQCOMMANEWLINESEMICOLONCOLONDOTLBRACELBRACKETLPARENPIPERBRACERBRACKETRPARENSCOPEREGEXPCLASSDEFDOELSEELSEIFENDFALSEIFMODULENILRETURNSELFTHENTRUEYIELD"
 
-var _TokenType_index = [...]uint16{0, 3, 10, 15, 21, 26, 29, 35, 41, 49, 56, 61, 68, 80, 90, 96, 104, 108, 113, 117, 122, 129, 131, 133, 135, 140, 145, 152, 161, 166, 169, 175, 183, 189, 193, 199, 207, 213, 218, 224, 229, 232, 234, 238, 244, 247, 252, 254, 260, 263, 269, 273, 277, 281, 286}
+var _TokenType_index = [...]uint16{0, 3, 10, 15, 21, 26, 29, 35, 41, 49, 56, 61, 68, 80, 90, 96, 104, 108, 113, 117, 122, 129, 132, 134, 136, 138, 143, 148, 155, 164, 169, 172, 178, 186, 192, 196, 202, 210, 216, 221, 227, 232, 235, 237, 241, 247, 250, 255, 257, 263, 266, 272, 276, 280, 284, 289}
 
 func (i TokenType) String() string {
 	if i < 0 || i >= TokenType(len(_TokenType_index)-1) {
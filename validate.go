@@ -0,0 +1,173 @@
+package cask
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate checks whether the Cask is complete and consistent. Besides the
+// Variant.Validate problems of each Variant, it reports the empty cask Token,
+// the missing variants, the variants which supported macOS releases are all
+// taken by the earlier ones and the variants overlapping with the earlier
+// ones. Like in Ruby, the first matching "if" or "elsif" branch wins, so the
+// earlier variants supporting only a part of the later variant releases aren't
+// reported, as well as the "else" branch. Returns nil if the Cask is valid.
+func (c *Cask) Validate() (errs []ValidationError) {
+	if c.Token == "" {
+		errs = append(errs, ValidationError{-1, "token", "cask token is empty"})
+	}
+
+	if len(c.Variants) == 0 {
+		errs = append(errs, ValidationError{-1, "variants", "cask has no variants"})
+	}
+
+	options := c.parseOptions()
+	for i, v := range c.Variants {
+		for _, err := range v.Validate() {
+			err.Variant = i
+			errs = append(errs, err)
+		}
+
+		if c.isUnreachable(i) {
+			errs = append(errs, ValidationError{
+				i,
+				"macos",
+				"supported macOS releases are all taken by the earlier variants",
+			})
+			continue
+		}
+
+		if isLatestVariant(v, options) {
+			continue
+		}
+
+		for j, other := range c.Variants[:i] {
+			if v.overlaps(other) && !other.shadows(v) {
+				errs = append(errs, ValidationError{
+					i,
+					"macos",
+					fmt.Sprintf("supported macOS releases overlap with variant %d", j),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// Validate checks whether the Variant is complete and consistent. It reports
+// the missing required stanzas, the checksum specified for the "latest"
+// version, the MinimumSupportedMacOS newer than the MaximumSupportedMacOS, the
+// unresolved interpolations in the URLs and the artifacts with empty values.
+// Returns nil if the Variant is valid.
+func (v *Variant) Validate() (errs []ValidationError) {
+	add := func(field string, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{-1, field, fmt.Sprintf(format, args...)})
+	}
+
+	isLatest := v.Version != nil && v.Version.Value == versionLatest
+
+	if v.Version == nil {
+		add("version", "missing required stanza")
+	}
+
+	if v.SHA256 == nil {
+		add("sha256", "missing required stanza")
+	} else if v.SHA256.Value != sha256NoCheck && isLatest {
+		add("sha256", "checksum should be :no_check for the latest version")
+	}
+
+	if v.URL == nil {
+		add("url", "missing required stanza")
+	}
+
+	if len(v.Names) == 0 {
+		add("names", "missing required stanza")
+	}
+
+	if v.Homepage == nil {
+		add("homepage", "missing required stanza")
+	}
+
+	if v.MinimumSupportedMacOS < v.MaximumSupportedMacOS {
+		add(
+			"macos",
+			"minimum supported release %s is newer than the maximum %s",
+			v.MinimumSupportedMacOS,
+			v.MaximumSupportedMacOS,
+		)
+	}
+
+	urls := []struct {
+		field string
+		value string
+	}{
+		{"url", v.GetURL().Value},
+		{"appcast", v.GetAppcast().URL},
+		{"livecheck", v.GetLivecheck().URL},
+		{"homepage", v.GetHomepage().Value},
+	}
+
+	for _, u := range urls {
+		if strings.Contains(u.value, "#{") {
+			add(u.field, "unresolved interpolation in '%s'", u.value)
+		}
+	}
+
+	for i, a := range v.Artifacts {
+		if strings.TrimSpace(a.Value) == "" {
+			add(fmt.Sprintf("artifacts[%d]", i), "%s artifact value is empty", a.Type)
+		}
+	}
+
+	return errs
+}
+
+// isUnreachable checks whether the Variant with the provided index isn't used
+// on any macOS release and CPU architecture, since all of them are taken by
+// the earlier variants. The variants with the invalid ranges are never
+// unreachable.
+func (c *Cask) isUnreachable(i int) bool {
+	v := c.Variants[i]
+	if v.MinimumSupportedMacOS < v.MaximumSupportedMacOS {
+		return false
+	}
+
+	options := c.parseOptions()
+	for mac := options.LatestMacOS(); mac <= options.OldestMacOS(); mac++ {
+		for _, arch := range []Arch{ArchIntel, ArchARM} {
+			if c.variantIndex(mac, arch) == i {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// overlaps checks whether the Variant supports at least one of the macOS
+// releases supported by the other Variant on the same CPU architecture. The
+// variants with the invalid ranges are never overlapping.
+func (v *Variant) overlaps(other *Variant) bool {
+	if v.MinimumSupportedMacOS < v.MaximumSupportedMacOS || other.MinimumSupportedMacOS < other.MaximumSupportedMacOS {
+		return false
+	}
+
+	// the lower MacOS values represent the newer releases
+	return v.Arch.Matches(other.Arch) &&
+		v.MaximumSupportedMacOS <= other.MinimumSupportedMacOS &&
+		other.MaximumSupportedMacOS <= v.MinimumSupportedMacOS
+}
+
+// shadows checks whether the Variant supports only a part of the macOS
+// releases and CPU architectures supported by the other Variant, which is the
+// case for an "if" branch narrowing the later "elsif" one.
+func (v *Variant) shadows(other *Variant) bool {
+	sameRange := v.MinimumSupportedMacOS == other.MinimumSupportedMacOS &&
+		v.MaximumSupportedMacOS == other.MaximumSupportedMacOS
+
+	return (other.Arch == ArchAll || v.Arch == other.Arch) &&
+		v.MinimumSupportedMacOS <= other.MinimumSupportedMacOS &&
+		v.MaximumSupportedMacOS >= other.MaximumSupportedMacOS &&
+		(!sameRange || v.Arch != other.Arch)
+}
@@ -0,0 +1,162 @@
+package cask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validationErrorStrings(errs []ValidationError) (result []string) {
+	for _, err := range errs {
+		result = append(result, err.Error())
+	}
+
+	return result
+}
+
+func TestCaskValidate(t *testing.T) {
+	testCases := map[string][]string{
+		"example-one.rb":                   nil,
+		"example-two.rb":                   nil,
+		"if-global-sha256-last.rb":         nil,
+		"if-three-versions-one-appcast.rb": nil,
+		"if-no-check.rb":                   nil,
+		"if-arch.rb":                       nil,
+		"on-arch.rb":                       nil,
+		"latest.rb": {
+			"variant 0: sha256: checksum should be :no_check for the latest version",
+		},
+		"unknown-stanzas.rb": {
+			"variant 0: homepage: missing required stanza",
+		},
+	}
+
+	for filename, expected := range testCases {
+		// preparations
+		c := NewCask(string(getTestdata(filename)))
+		assert.Nil(t, c.Parse(), filename)
+
+		// test
+		assert.Equal(t, expected, validationErrorStrings(c.Validate()), filename)
+	}
+}
+
+func TestCaskValidateEmpty(t *testing.T) {
+	// test
+	assert.Equal(t, []string{
+		"token: cask token is empty",
+		"variants: cask has no variants",
+	}, validationErrorStrings(NewCask("").Validate()))
+}
+
+func TestCaskValidateOverlaps(t *testing.T) {
+	// preparations
+	c := NewCask("")
+	c.Token = "example"
+
+	ranges := [][2]MacOS{
+		{MacOSMavericks, MacOSYosemite},
+		{MacOSYosemite, MacOSElCapitan},
+		{MacOSSierra, MacOSHighSierra},
+		{MacOSHighSierra, MacOSSierra},
+		{MacOSMavericks, MacOSHighSierra},
+	}
+
+	for _, r := range ranges {
+		v := NewVariant()
+		v.Version = NewVersion("1.0.0")
+		v.SHA256 = NewSHA256("cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435")
+		v.URL = NewURL("https://example.com/app_#{version}.dmg")
+		v.AddName(NewName("Example"))
+		v.Homepage = NewHomepage("https://example.com/")
+		v.MinimumSupportedMacOS = r[0]
+		v.MaximumSupportedMacOS = r[1]
+		c.AddVariant(v)
+	}
+
+	// test
+	assert.Equal(t, []string{
+		"variant 1: macos: supported macOS releases overlap with variant 0",
+		"variant 3: macos: minimum supported release macOS High Sierra (10.13) is newer than the maximum macOS Sierra (10.12)",
+		"variant 4: macos: supported macOS releases are all taken by the earlier variants",
+	}, validationErrorStrings(c.Validate()))
+
+	// test (architectures)
+	c.Variants = c.Variants[:3]
+	c.Variants[0].Arch = ArchIntel
+	c.Variants[1].Arch = ArchARM
+	assert.Nil(t, c.Validate())
+
+	c.Variants[1].MinimumSupportedMacOS = MacOSMavericks
+	assert.Nil(t, c.Validate())
+
+	c.Variants[2].Arch = ArchARM
+	c.Variants[2].MinimumSupportedMacOS = MacOSYosemite
+	c.Variants[2].MaximumSupportedMacOS = MacOSElCapitan
+	assert.Equal(t, []string{
+		"variant 2: macos: supported macOS releases are all taken by the earlier variants",
+	}, validationErrorStrings(c.Validate()))
+
+	// test (narrowing "if" branches and the "else" branch)
+	c = NewCask(string(getTestdata("if-three-versions-one-appcast.rb")))
+	assert.Nil(t, c.Parse())
+	assert.Nil(t, c.Validate())
+
+	c.Variants[1].MinimumSupportedMacOS = MacOSSierra
+	c.Variants[1].MaximumSupportedMacOS = MacOSHighSierra
+	assert.Nil(t, c.Validate())
+
+	c.Variants[0].MinimumSupportedMacOS = MacOSElCapitan
+	c.Variants[0].MaximumSupportedMacOS = MacOSSierra
+	assert.Equal(t, []string{
+		"variant 1: macos: supported macOS releases overlap with variant 0",
+	}, validationErrorStrings(c.Validate()))
+}
+
+func TestVariantValidate(t *testing.T) {
+	// preparations
+	v := NewVariant()
+
+	// test (missing stanzas)
+	assert.Equal(t, []string{
+		"version: missing required stanza",
+		"sha256: missing required stanza",
+		"url: missing required stanza",
+		"names: missing required stanza",
+		"homepage: missing required stanza",
+	}, validationErrorStrings(v.Validate()))
+
+	v.Version = NewVersion("1.0.0")
+	assert.Contains(t, validationErrorStrings(v.Validate()), "sha256: missing required stanza")
+
+	v.SHA256 = NewSHA256("no_check")
+	assert.NotContains(t, validationErrorStrings(v.Validate()), "sha256: missing required stanza")
+
+	v.Version = NewVersion("latest")
+	assert.NotContains(t, validationErrorStrings(v.Validate()), "sha256: checksum should be :no_check for the latest version")
+
+	// test (unresolved interpolations and empty artifacts)
+	v.SHA256 = NewSHA256("cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435")
+	v.URL = NewURL("https://example.com/#{version.unknown}/app.dmg")
+	v.Appcast = NewAppcast("https://example.com/#{unknown}/appcast.xml", "")
+	v.AddName(NewName("Example"))
+	v.Homepage = NewHomepage("https://example.com/#{token}/")
+	v.AddArtifact(NewArtifact(ArtifactApp, "Example.app"))
+	v.AddArtifact(NewArtifact(ArtifactBinary, " "))
+
+	assert.Equal(t, []string{
+		"sha256: checksum should be :no_check for the latest version",
+		"url: unresolved interpolation in 'https://example.com/#{version.unknown}/app.dmg'",
+		"appcast: unresolved interpolation in 'https://example.com/#{unknown}/appcast.xml'",
+		"homepage: unresolved interpolation in 'https://example.com/#{token}/'",
+		"artifacts[1]: binary artifact value is empty",
+	}, validationErrorStrings(v.Validate()))
+
+	// test (valid)
+	v.SHA256 = NewSHA256("no_check")
+	v.URL = NewURL("https://example.com/app_#{version}.dmg")
+	v.Appcast = NewAppcast("https://example.com/appcast.xml", "")
+	v.Homepage = NewHomepage("https://example.com/")
+	v.Artifacts = v.Artifacts[:1]
+	assert.Nil(t, v.Validate())
+}
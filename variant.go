@@ -1,5 +1,10 @@
 package cask
 
+import (
+	"fmt"
+	"strings"
+)
+
 // A Variant represents a single cask variant.
 type Variant struct {
 	// Version specifies the version stanza.
@@ -14,6 +19,9 @@ type Variant struct {
 	// Appcast specifies the appcast stanza.
 	Appcast *Appcast
 
+	// Livecheck specifies the livecheck stanza.
+	Livecheck *Livecheck
+
 	// Names specify the application names. Each cask can have multiple names.
 	Names []*Name
 
@@ -23,6 +31,10 @@ type Variant struct {
 	// Artifacts specify artifact stanzas.
 	Artifacts []*Artifact
 
+	// Stanzas specify all stanzas parsed by the custom StanzaParser functions
+	// grouped by the stanza name.
+	Stanzas map[string][]Stanza
+
 	// MinimumSupportedMacOS specifies the minimum supported macOS release. By
 	// default each cask uses the latest stable macOS release.
 	MinimumSupportedMacOS MacOS
@@ -30,6 +42,33 @@ type Variant struct {
 	// MaximumSupportedMacOS specifies the maximum supported macOS release. By
 	// default each cask uses the latest stable macOS release.
 	MaximumSupportedMacOS MacOS
+
+	// Arch specifies the supported CPU architecture. By default each cask
+	// supports all architectures (ArchAll).
+	Arch Arch
+
+	// variables specify the interpolation variables set during parsing. They
+	// override the DefaultInterpolationVariables.
+	variables map[string]string
+
+	// raw specifies the Variant before its interpolations were resolved, so its
+	// templates can be used with another version. It's nil unless the
+	// interpolations were resolved.
+	raw *Variant
+}
+
+// A FieldChange represents a single Variant field value change.
+type FieldChange struct {
+	// Field specifies the changed field name: "version", "sha256", "url",
+	// "appcast", "livecheck", "homepage", "names[i]", "artifacts[i]" or
+	// "artifacts[i].target".
+	Field string
+
+	// Old specifies the interpolated field value before the change.
+	Old string
+
+	// New specifies the interpolated field value after the change.
+	New string
 }
 
 // NewVariant returns a new Variant instance pointer.
@@ -47,6 +86,22 @@ func (v *Variant) AddArtifact(artifact *Artifact) {
 	v.Artifacts = append(v.Artifacts, artifact)
 }
 
+// AddStanza adds a new Stanza to the Variant.Stanzas under the specified
+// stanza name.
+func (v *Variant) AddStanza(name string, stanza Stanza) {
+	if v.Stanzas == nil {
+		v.Stanzas = make(map[string][]Stanza)
+	}
+
+	v.Stanzas[name] = append(v.Stanzas[name], stanza)
+}
+
+// GetStanzas returns all stanzas from the Variant.Stanzas with the specified
+// stanza name.
+func (v *Variant) GetStanzas(name string) []Stanza {
+	return v.Stanzas[name]
+}
+
 // GetVersion returns the Version struct from the existing Variant.Version
 // struct pointer.
 func (v *Variant) GetVersion() Version {
@@ -68,14 +123,11 @@ func (v *Variant) GetSHA256() SHA256 {
 }
 
 // GetURL returns the URL struct from the existing Variant.URL struct pointer
-// and interpolates the version into the Variant.URL.Value if available.
+// and resolves all interpolations in the Variant.URL.Value if available.
 func (v *Variant) GetURL() (u URL) {
 	if v.URL != nil {
 		u = *(v.URL)
-
-		if v.Version != nil && v.Version.HasVersionStringInterpolation(u.Value) {
-			u.Value = v.Version.InterpolateIntoString(u.Value)
-		}
+		u.Value = v.interpolate(u.Value)
 
 		return u
 	}
@@ -84,15 +136,12 @@ func (v *Variant) GetURL() (u URL) {
 }
 
 // GetAppcast returns the Appcast struct from the existing Variant.Appcast
-// struct pointer and interpolates the version into the Variant.Appcast.URL if
+// struct pointer and resolves all interpolations in the Variant.Appcast.URL if
 // available.
 func (v *Variant) GetAppcast() (a Appcast) {
 	if v.Appcast != nil {
 		a = *(v.Appcast)
-
-		if v.Version != nil && v.Version.HasVersionStringInterpolation(a.URL) {
-			a.URL = v.Version.InterpolateIntoString(a.URL)
-		}
+		a.URL = v.interpolate(a.URL)
 
 		return a
 	}
@@ -100,15 +149,26 @@ func (v *Variant) GetAppcast() (a Appcast) {
 	return Appcast{}
 }
 
+// GetLivecheck returns the Livecheck struct from the existing
+// Variant.Livecheck struct pointer and resolves all interpolations in the
+// Variant.Livecheck.URL if available.
+func (v *Variant) GetLivecheck() (l Livecheck) {
+	if v.Livecheck != nil {
+		l = *(v.Livecheck)
+		l.URL = v.interpolate(l.URL)
+
+		return l
+	}
+
+	return Livecheck{}
+}
+
 // GetNames returns the []Name slice from the existing []Variant.Names slice
-// pointer and interpolates the version into each name if available.
+// pointer and resolves all interpolations in each name if available.
 func (v *Variant) GetNames() (n []Name) {
 	for _, name := range v.Names {
 		newName := *name
-
-		if v.Version != nil && v.Version.HasVersionStringInterpolation(name.Value) {
-			newName.Value = v.Version.InterpolateIntoString(name.Value)
-		}
+		newName.Value = v.interpolate(name.Value)
 
 		n = append(n, newName)
 	}
@@ -117,15 +177,12 @@ func (v *Variant) GetNames() (n []Name) {
 }
 
 // GetHomepage returns the Homepage struct from the existing Variant.Homepage
-// struct pointer and interpolates the version into the Variant.Homepage.Value
+// struct pointer and resolves all interpolations in the Variant.Homepage.Value
 // if available.
 func (v *Variant) GetHomepage() (h Homepage) {
 	if v.Homepage != nil {
 		h = *(v.Homepage)
-
-		if v.Version != nil && v.Version.HasVersionStringInterpolation(h.Value) {
-			h.Value = v.Version.InterpolateIntoString(h.Value)
-		}
+		h.Value = v.interpolate(h.Value)
 
 		return h
 	}
@@ -134,18 +191,323 @@ func (v *Variant) GetHomepage() (h Homepage) {
 }
 
 // GetArtifacts returns the []Artifacts slice from the existing
-// []Variant.Artifacts slice pointer and interpolates the version into each
-// artifact value if available.
+// []Variant.Artifacts slice pointer and resolves all interpolations in each
+// artifact value and target if available.
 func (v *Variant) GetArtifacts() (a []Artifact) {
 	for _, artifact := range v.Artifacts {
 		newArtifact := *artifact
-
-		if v.Version != nil && v.Version.HasVersionStringInterpolation(artifact.Value) {
-			newArtifact.Value = v.Version.InterpolateIntoString(artifact.Value)
-		}
+		newArtifact.Value = v.interpolate(artifact.Value)
+		newArtifact.Target = v.interpolate(artifact.Target)
 
 		a = append(a, newArtifact)
 	}
 
 	return a
 }
+
+// WithVersion returns a copy of the Variant with the Variant.Version value
+// replaced by the provided one. All other stanzas are copied as is, so the
+// accessors of the returned Variant resolve the interpolations in the existing
+// templates using the new version. The Variant.SHA256 isn't changed and should
+// be updated separately.
+//
+// If the interpolations in the Variant are already resolved, like in the
+// casks parsed with the eager interpolation or in the Cask.VariantFor result,
+// the original templates are used and the returned Variant has the
+// interpolations resolved as well.
+func (v *Variant) WithVersion(version string) *Variant {
+	source := v
+	if v.raw != nil {
+		source = v.raw
+	}
+
+	variant := source.clone()
+	for name, value := range v.variables {
+		variant.variables[name] = value
+	}
+
+	if variant.Version != nil {
+		variant.Version.Value = version
+	} else {
+		variant.Version = NewVersion(version)
+	}
+
+	if v.raw != nil {
+		variant.resolveInterpolations()
+	}
+
+	return variant
+}
+
+// VersionChanges returns all Variant fields, which interpolated values would
+// change if the Variant.Version would be replaced by the provided one.
+func (v *Variant) VersionChanges(version string) []FieldChange {
+	return v.changes(v.WithVersion(version))
+}
+
+// Interpolator returns the Interpolator used by the Variant accessors. It uses
+// the Variant.Version and the DefaultInterpolationVariables overridden by the
+// variables set during parsing (for example, the cask "token"). Unless
+// specified during parsing, the "#{arch}" is the Arch.Value of the specific
+// Variant.Arch.
+func (v *Variant) Interpolator() *Interpolator {
+	variables := DefaultInterpolationVariables()
+	if v.Arch != ArchAll {
+		variables[VariableArch] = v.Arch.Value()
+	}
+
+	for name, value := range v.variables {
+		variables[name] = value
+	}
+
+	return NewInterpolator(v.Version, variables)
+}
+
+// InterpolationErrors returns errors for all interpolations in the Variant
+// stanza values that can't be resolved.
+func (v *Variant) InterpolationErrors() (errs []error) {
+	i := v.Interpolator()
+
+	values := []string{}
+	if v.URL != nil {
+		values = append(values, v.URL.Value)
+	}
+
+	if v.Appcast != nil {
+		values = append(values, v.Appcast.URL)
+	}
+
+	if v.Livecheck != nil {
+		values = append(values, v.Livecheck.URL)
+	}
+
+	for _, n := range v.Names {
+		values = append(values, n.Value)
+	}
+
+	if v.Homepage != nil {
+		values = append(values, v.Homepage.Value)
+	}
+
+	for _, a := range v.Artifacts {
+		values = append(values, a.Value, a.Target)
+	}
+
+	for _, value := range values {
+		_, e := i.Interpolate(value)
+		errs = append(errs, e...)
+	}
+
+	return errs
+}
+
+// interpolate resolves all interpolations in the provided string using the
+// Variant.Interpolator. The interpolations that can't be resolved are left as
+// is.
+func (v *Variant) interpolate(str string) string {
+	if !strings.Contains(str, "#{") {
+		return str
+	}
+
+	result, _ := v.Interpolator().Interpolate(str)
+	return result
+}
+
+// resolveInterpolations replaces all stanzas that support interpolations with
+// their interpolated copies. The copies are used since the global stanzas are
+// shared between multiple variants. The Variant with the original templates is
+// kept as the Variant.raw.
+func (v *Variant) resolveInterpolations() {
+	if v.raw == nil {
+		v.raw = v.clone()
+	}
+
+	if v.URL != nil {
+		u := v.GetURL()
+		v.URL = &u
+	}
+
+	if v.Appcast != nil {
+		a := v.GetAppcast()
+		v.Appcast = &a
+	}
+
+	if v.Livecheck != nil {
+		l := v.GetLivecheck()
+		v.Livecheck = &l
+	}
+
+	if v.Homepage != nil {
+		h := v.GetHomepage()
+		v.Homepage = &h
+	}
+
+	names := v.GetNames()
+	v.Names = nil
+	for i := range names {
+		v.AddName(&names[i])
+	}
+
+	artifacts := v.GetArtifacts()
+	v.Artifacts = nil
+	for i := range artifacts {
+		v.AddArtifact(&artifacts[i])
+	}
+}
+
+// changes returns all fields, which interpolated values differ in the other
+// Variant. The names and artifacts are compared by their index.
+func (v *Variant) changes(other *Variant) (changes []FieldChange) {
+	add := func(field string, before string, after string) {
+		if before != after {
+			changes = append(changes, FieldChange{field, before, after})
+		}
+	}
+
+	add("version", v.GetVersion().Value, other.GetVersion().Value)
+	add("sha256", v.GetSHA256().Value, other.GetSHA256().Value)
+	add("url", v.GetURL().Value, other.GetURL().Value)
+	add("appcast", v.GetAppcast().URL, other.GetAppcast().URL)
+	add("livecheck", v.GetLivecheck().URL, other.GetLivecheck().URL)
+
+	names, otherNames := v.GetNames(), other.GetNames()
+	for i := 0; i < len(names) || i < len(otherNames); i++ {
+		var before, after string
+		if i < len(names) {
+			before = names[i].Value
+		}
+		if i < len(otherNames) {
+			after = otherNames[i].Value
+		}
+		add(fmt.Sprintf("names[%d]", i), before, after)
+	}
+
+	add("homepage", v.GetHomepage().Value, other.GetHomepage().Value)
+
+	artifacts, otherArtifacts := v.GetArtifacts(), other.GetArtifacts()
+	for i := 0; i < len(artifacts) || i < len(otherArtifacts); i++ {
+		var before, after Artifact
+		if i < len(artifacts) {
+			before = artifacts[i]
+		}
+		if i < len(otherArtifacts) {
+			after = otherArtifacts[i]
+		}
+		add(fmt.Sprintf("artifacts[%d]", i), before.Value, after.Value)
+		add(fmt.Sprintf("artifacts[%d].target", i), before.Target, after.Target)
+	}
+
+	return changes
+}
+
+// clone returns a copy of the Variant with all stanzas copied, so they can be
+// changed without affecting the original Variant. The custom stanzas from the
+// Variant.Stanzas are shared.
+func (v *Variant) clone() *Variant {
+	variant := *v
+
+	if v.Version != nil {
+		version := *v.Version
+		variant.Version = &version
+	}
+
+	if v.SHA256 != nil {
+		sha256 := *v.SHA256
+		variant.SHA256 = &sha256
+	}
+
+	if v.URL != nil {
+		u := *v.URL
+		variant.URL = &u
+	}
+
+	if v.Appcast != nil {
+		appcast := *v.Appcast
+		variant.Appcast = &appcast
+	}
+
+	if v.Livecheck != nil {
+		livecheck := *v.Livecheck
+		variant.Livecheck = &livecheck
+	}
+
+	if v.Homepage != nil {
+		homepage := *v.Homepage
+		variant.Homepage = &homepage
+	}
+
+	variant.Names = nil
+	for _, n := range v.Names {
+		name := *n
+		variant.AddName(&name)
+	}
+
+	variant.Artifacts = nil
+	for _, a := range v.Artifacts {
+		artifact := *a
+		variant.AddArtifact(&artifact)
+	}
+
+	variant.Stanzas = nil
+	for name, stanzas := range v.Stanzas {
+		for _, stanza := range stanzas {
+			variant.AddStanza(name, stanza)
+		}
+	}
+
+	variant.variables = make(map[string]string, len(v.variables))
+	for name, value := range v.variables {
+		variant.variables[name] = value
+	}
+
+	return &variant
+}
+
+// resolved returns a copy of the Variant with all interpolations resolved.
+// Unless specified during parsing, the "#{arch}" interpolations are resolved
+// using the Arch.Value of the Variant.Arch or the provided Arch.
+func (v *Variant) resolved(arch Arch) *Variant {
+	variant := v.clone()
+	if _, ok := variant.variables[VariableArch]; !ok && variant.Arch == ArchAll && arch != ArchAll {
+		variant.variables[VariableArch] = arch.Value()
+	}
+	variant.resolveInterpolations()
+
+	return variant
+}
+
+// supports checks whether the provided macOS release is between the
+// Variant.MinimumSupportedMacOS and Variant.MaximumSupportedMacOS. The newer
+// releases have the lower MacOS values.
+func (v *Variant) supports(mac MacOS) bool {
+	return mac >= v.MaximumSupportedMacOS && mac <= v.MinimumSupportedMacOS
+}
+
+// macOSRange returns the supported macOS versions range. For example:
+// "10.10-10.12" or "10.13".
+func (v *Variant) macOSRange() string {
+	if v.MinimumSupportedMacOS == v.MaximumSupportedMacOS {
+		return v.MinimumSupportedMacOS.Version()
+	}
+
+	return fmt.Sprintf("%s-%s", v.MinimumSupportedMacOS.Version(), v.MaximumSupportedMacOS.Version())
+}
+
+// label returns the supported macOS versions range and CPU architecture
+// identifying the Variant. For example: "10.10-10.12 (intel)".
+func (v *Variant) label() string {
+	return fmt.Sprintf("%s (%s)", v.macOSRange(), v.Arch.String())
+}
+
+// isEmpty checks whether the Variant doesn't have any stanzas.
+func (v *Variant) isEmpty() bool {
+	return v.Version == nil &&
+		v.SHA256 == nil &&
+		v.URL == nil &&
+		v.Appcast == nil &&
+		v.Livecheck == nil &&
+		len(v.Names) == 0 &&
+		v.Homepage == nil &&
+		len(v.Artifacts) == 0 &&
+		len(v.Stanzas) == 0
+}
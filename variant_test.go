@@ -170,3 +170,143 @@ func TestGetArtifacts(t *testing.T) {
 	assert.Equal(t, v.Artifacts[0].Value, actual[0].Value)
 	assert.Equal(t, "Test 2.0.0.app", actual[1].Value)
 }
+
+func TestAddStanza(t *testing.T) {
+	// preparations
+	v := NewVariant()
+
+	// test
+	assert.Len(t, v.GetStanzas("test"), 0)
+	v.AddStanza("test", NewName("first"))
+	v.AddStanza("test", NewName("second"))
+	assert.Len(t, v.GetStanzas("test"), 2)
+	assert.Equal(t, "second", v.GetStanzas("test")[1].String())
+}
+
+func TestVariantInterpolator(t *testing.T) {
+	// preparations
+	v := NewVariant()
+	v.Version = NewVersion("1.0.0")
+	v.variables = map[string]string{VariableAppdir: "/Custom", VariableToken: "example"}
+
+	// test
+	i := v.Interpolator()
+	assert.Equal(t, v.Version, i.Version)
+	assert.Equal(t, map[string]string{
+		"appdir":        "/Custom",
+		"caskroom_path": "/usr/local/Caskroom",
+		"token":         "example",
+	}, i.Variables)
+
+	// test (architecture)
+	v.Arch = ArchARM
+	assert.Equal(t, "arm64", v.Interpolator().Variables[VariableArch])
+
+	v.variables[VariableArch] = "aarch64"
+	assert.Equal(t, "aarch64", v.Interpolator().Variables[VariableArch])
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	// preparations
+	v := NewVariant()
+	v.Version = NewVersion("1.0.0")
+	v.URL = NewURL("https://example.com/#{language}/app_#{version}.dmg")
+	v.AddArtifact(NewArtifact(ArtifactBinary, "#{appdir}/Example.app/Contents/MacOS/example"))
+
+	// test
+	errs := v.InterpolationErrors()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "unresolved interpolation '#{language}': variable 'language' is not set", errs[0].Error())
+	assert.Equal(t, "https://example.com/#{language}/app_1.0.0.dmg", v.GetURL().Value)
+	assert.Equal(t, "/Applications/Example.app/Contents/MacOS/example", v.GetArtifacts()[0].Value)
+}
+
+func TestParseWithInterpolationVariables(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("example-one.rb")), WithInterpolationVariables(map[string]string{
+		VariableAppdir: "/Users/example/Applications",
+	}))
+
+	// test
+	assert.Nil(t, c.Parse())
+	assert.Equal(
+		t,
+		"/Users/example/Applications/Example 2.0.app/Contents/MacOS/example-one",
+		c.Variants[0].GetArtifacts()[2].Value,
+	)
+	assert.Equal(t, "example-one", c.Variants[0].Interpolator().Variables[VariableToken])
+	assert.Len(t, c.Variants[0].InterpolationErrors(), 0)
+}
+
+func TestWithVersion(t *testing.T) {
+	// preparations
+	v := NewVariant()
+	v.Version = NewVersion("1.0.0,100")
+	v.Version.IsGlobal = true
+	v.SHA256 = NewSHA256("92521fc3cbd964bdc9f584a991b89fddaa5754ed1cc96d6d42445338669c1305")
+	v.URL = NewURL("https://example.com/app_#{version.before_comma}_#{version.after_comma}.dmg")
+	v.Appcast = NewAppcast("https://example.com/#{version.major}/sparkle.xml", "")
+	v.AddName(NewName("Example #{version.major}"))
+	v.Homepage = NewHomepage("https://example.com/")
+	v.AddArtifact(NewArtifact(ArtifactApp, "Example #{version.major}.app"))
+
+	// test
+	actual := v.WithVersion("2.1.0,200")
+	assert.Equal(t, "2.1.0,200", actual.GetVersion().Value)
+	assert.True(t, actual.Version.IsGlobal)
+	assert.Equal(t, v.GetSHA256(), actual.GetSHA256())
+	assert.Equal(t, "https://example.com/app_2.1.0_200.dmg", actual.GetURL().Value)
+	assert.Equal(t, "https://example.com/2/sparkle.xml", actual.GetAppcast().URL)
+	assert.Equal(t, "Example 2", actual.GetNames()[0].Value)
+	assert.Equal(t, "https://example.com/", actual.GetHomepage().Value)
+	assert.Equal(t, "Example 2.app", actual.GetArtifacts()[0].Value)
+
+	// test (original is unchanged)
+	assert.Equal(t, "1.0.0,100", v.GetVersion().Value)
+	assert.Equal(t, "https://example.com/app_1.0.0_100.dmg", v.GetURL().Value)
+	actual.Names[0].Value = "Changed"
+	assert.Equal(t, "Example 1", v.GetNames()[0].Value)
+
+	// test (without version)
+	actual = NewVariant().WithVersion("1.0.0")
+	assert.Equal(t, "1.0.0", actual.GetVersion().Value)
+
+	// test (eager interpolation)
+	c := NewCask(string(getTestdata("example-one.rb")), WithEagerInterpolation(true))
+	assert.Nil(t, c.Parse())
+
+	actual = c.Variants[0].WithVersion("3.1.0")
+	assert.Equal(t, "https://example.com/app_3.1.0.dmg", actual.URL.Value)
+	assert.Equal(t, "Example 3.1.app", actual.Artifacts[0].Value)
+	assert.Equal(t, "https://example.com/app_4.0.0.dmg", actual.WithVersion("4.0.0").URL.Value)
+	assert.Equal(t, "https://example.com/app_2.0.0.dmg", c.Variants[0].URL.Value)
+	assert.Len(t, c.Variants[0].VersionChanges("3.1.0"), 6)
+
+	// test (resolved variant)
+	c = NewCask(string(getTestdata("on-arch.rb")))
+	assert.Nil(t, c.Parse())
+
+	v, err := c.VariantFor(MacOSHighSierra, ArchARM)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/app_3.0.0_arm64.dmg", v.WithVersion("3.0.0").URL.Value)
+}
+
+func TestVersionChanges(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("example-one.rb")))
+	assert.Nil(t, c.Parse())
+	v := c.Variants[0]
+
+	// test
+	assert.Equal(t, []FieldChange{
+		{"version", "2.0.0", "3.1.0"},
+		{"url", "https://example.com/app_2.0.0.dmg", "https://example.com/app_3.1.0.dmg"},
+		{"appcast", "https://example.com/sparkle/2/appcast.xml", "https://example.com/sparkle/3/appcast.xml"},
+		{"artifacts[0]", "Example 2.0.app", "Example 3.1.app"},
+		{"artifacts[1]", "Example 2.0 Uninstaller.app", "Example 3.1 Uninstaller.app"},
+		{"artifacts[2]", "/Applications/Example 2.0.app/Contents/MacOS/example-one", "/Applications/Example 3.1.app/Contents/MacOS/example-one"},
+	}, v.VersionChanges("3.1.0"))
+
+	// test (same version)
+	assert.Empty(t, v.VersionChanges("2.0.0"))
+}
@@ -33,54 +33,88 @@ func (v Version) HasVersionStringInterpolation(str string) bool {
 	return false
 }
 
+// versionPartDividers specify the dividers between the Version.Value parts
+// returned by Version.Parts.
+const versionPartDividers = ".,:"
+
+// Parts splits the Version.Value into parts by dots, commas and colons the same
+// way as Homebrew does when extracting the major, minor and patch versions:
+// "12.10.3,1000" results in "12", "10", "3" and "1000". Each part can contain
+// any other characters ("1.2-beta3" results in "1" and "2-beta3"). The
+// splitting stops at the first empty part, so the value starting with a
+// divider results in nil.
+func (v Version) Parts() (parts []string) {
+	value := v.Value
+	for {
+		i := strings.IndexAny(value, versionPartDividers)
+		switch {
+		case i < 0 && value != "":
+			return append(parts, value)
+		case i <= 0:
+			return parts
+		}
+
+		parts = append(parts, value[:i])
+		value = value[i+1:]
+	}
+}
+
+// part returns the Version.Parts part by the provided index. Returns an error,
+// which includes the specified method name, if there is no such part.
+func (v Version) part(method string, index int) (string, error) {
+	parts := v.Parts()
+	if index < len(parts) {
+		return parts[index], nil
+	}
+	return "", fmt.Errorf(`version "%s": no %s() match`, v.Value, method)
+}
+
+// joinParts returns up to the provided number of Version.Parts joined by dots.
+// Returns an error, which includes the specified method name, if there are no
+// parts.
+func (v Version) joinParts(method string, count int) (string, error) {
+	parts := v.Parts()
+	if len(parts) == 0 {
+		return "", fmt.Errorf(`version "%s": no %s() match`, v.Value, method)
+	}
+
+	if len(parts) > count {
+		parts = parts[:count]
+	}
+
+	return strings.Join(parts, "."), nil
+}
+
 // Major extracts the major semantic version part from Version.Value and returns
 // the result string.
 func (v Version) Major() (string, error) {
-	re := regexp.MustCompile(`^\d`)
-	if re.MatchString(v.Value) {
-		return re.FindAllString(v.Value, -1)[0], nil
-	}
-	return "", fmt.Errorf(`version "%s": no Major() match`, v.Value)
+	return v.part("Major", 0)
 }
 
 // Minor extracts the minor semantic version part from Version.Value and returns
 // the result string.
 func (v Version) Minor() (string, error) {
-	re := regexp.MustCompile(`^(?:\d)\.(\d)`)
-	if re.MatchString(v.Value) {
-		return re.FindAllStringSubmatch(v.Value, -1)[0][1], nil
-	}
-	return "", fmt.Errorf(`version "%s": no Minor() match`, v.Value)
+	return v.part("Minor", 1)
 }
 
 // Patch extracts the patch semantic version part from Version.Value and returns
 // the result string.
 func (v Version) Patch() (string, error) {
-	re := regexp.MustCompile(`^(?:\d)\.(?:\d)\.(\d)`)
-	if re.MatchString(v.Value) {
-		return re.FindAllStringSubmatch(v.Value, -1)[0][1], nil
-	}
-	return "", fmt.Errorf(`version "%s": no Patch() match`, v.Value)
+	return v.part("Patch", 2)
 }
 
 // MajorMinor extracts the major and minor semantic version parts from
-// Version.Value and returns the result string.
+// Version.Value and returns the result string. If there is no minor part, only
+// the major one is returned.
 func (v Version) MajorMinor() (string, error) {
-	re := regexp.MustCompile(`^((?:\d)\.(?:\d))`)
-	if re.MatchString(v.Value) {
-		return re.FindAllString(v.Value, -1)[0], nil
-	}
-	return "", fmt.Errorf(`version "%s": no MajorMinor() match`, v.Value)
+	return v.joinParts("MajorMinor", 2)
 }
 
 // MajorMinorPatch extracts the major, minor and patch semantic version parts
-// from Version.Value and returns the result string.
+// from Version.Value and returns the result string. The missing minor and patch
+// parts are omitted.
 func (v Version) MajorMinorPatch() (string, error) {
-	re := regexp.MustCompile(`^((?:\d)\.(?:\d)\.(?:\d))`)
-	if re.MatchString(v.Value) {
-		return re.FindAllString(v.Value, -1)[0], nil
-	}
-	return "", fmt.Errorf(`version "%s": no MajorMinorPatch() match`, v.Value)
+	return v.joinParts("MajorMinorPatch", 3)
 }
 
 // BeforeComma extracts the Version.Value part before comma and returns the
@@ -152,118 +186,170 @@ func (v Version) DotsToHyphens() (string, error) {
 	return "", fmt.Errorf(`version "%s": no DotsToHyphens() match`, v.Value)
 }
 
-// InterpolateIntoString interpolates existing version into the provided string
-// with Ruby interpolation syntax.
-func (v Version) InterpolateIntoString(str string) (result string) {
-	result = str
+// DotsToSlashes converts all Version.Value dots to slashes and returns the
+// result string.
+func (v Version) DotsToSlashes() (string, error) {
+	return v.replaceAll("DotsToSlashes", `\.`, "/")
+}
 
-	regexInterpolations := regexp.MustCompile(`(#{version})|(#{version\.[^}]*.[^{]*})`)
-	regexAllMethods := regexp.MustCompile(`(?:^#{version\.)(.*)}`)
+// HyphensToDots converts all Version.Value hyphens to dots and returns the
+// result string.
+func (v Version) HyphensToDots() (string, error) {
+	return v.replaceAll("HyphensToDots", `-`, ".")
+}
 
-	// find all version interpolations
-	matches := regexInterpolations.FindAllStringSubmatch(str, -1)
+// HyphensToUnderscores converts all Version.Value hyphens to underscores and
+// returns the result string.
+func (v Version) HyphensToUnderscores() (string, error) {
+	return v.replaceAll("HyphensToUnderscores", `-`, "_")
+}
 
-	// for every version interpolation
-	for _, m := range matches {
-		match := m[0]
+// UnderscoresToDots converts all Version.Value underscores to dots and returns
+// the result string.
+func (v Version) UnderscoresToDots() (string, error) {
+	return v.replaceAll("UnderscoresToDots", `_`, ".")
+}
 
-		// extract all methods
-		methodsAll := regexAllMethods.FindAllStringSubmatch(match, -1)
-		if len(methodsAll) < 1 {
-			// when no methods, then it's just a version replace
-			re := regexp.MustCompile(regexp.QuoteMeta(match))
-			result = re.ReplaceAllString(result, v.Value)
-			continue
-		}
+// UnderscoresToHyphens converts all Version.Value underscores to hyphens and
+// returns the result string.
+func (v Version) UnderscoresToHyphens() (string, error) {
+	return v.replaceAll("UnderscoresToHyphens", `_`, "-")
+}
 
-		methods := strings.Split(methodsAll[0][1], ".")
-
-		// for every method
-		part := v.Value
-		for _, method := range methods {
-			switch method {
-			case "major":
-				r, err := NewVersion(part).Major()
-				if err == nil {
-					part = r
-				}
-				break
-			case "minor":
-				r, err := NewVersion(part).Minor()
-				if err == nil {
-					part = r
-				}
-				break
-			case "patch":
-				r, err := NewVersion(part).Patch()
-				if err == nil {
-					part = r
-				}
-				break
-			case "major_minor":
-				r, err := NewVersion(part).MajorMinor()
-				if err == nil {
-					part = r
-				}
-				break
-			case "major_minor_patch":
-				r, err := NewVersion(part).MajorMinorPatch()
-				if err == nil {
-					part = r
-				}
-				break
-			case "before_comma":
-				r, err := NewVersion(part).BeforeComma()
-				if err == nil {
-					part = r
-				}
-				break
-			case "after_comma":
-				r, err := NewVersion(part).AfterComma()
-				if err == nil {
-					part = r
-				}
-				break
-			case "before_colon":
-				r, err := NewVersion(part).BeforeColon()
-				if err == nil {
-					part = r
-				}
-				break
-			case "after_colon":
-				r, err := NewVersion(part).AfterColon()
-				if err == nil {
-					part = r
-				}
-				break
-			case "no_dots":
-				r, err := NewVersion(part).NoDots()
-				if err == nil {
-					part = r
-				}
-				break
-			case "dots_to_underscores":
-				r, err := NewVersion(part).DotsToUnderscores()
-				if err == nil {
-					part = r
-				}
-				break
-			case "dots_to_hyphens":
-				r, err := NewVersion(part).DotsToHyphens()
-				if err == nil {
-					part = r
-				}
-				break
-			default:
-				// if one of the methods is unknown, then return full string without any replacements
-				return result
-			}
-		}
+// NoHyphens removes all Version.Value hyphens and returns the result string.
+func (v Version) NoHyphens() (string, error) {
+	return v.replaceAll("NoHyphens", `-`, "")
+}
 
-		re := regexp.MustCompile(regexp.QuoteMeta(match))
-		result = re.ReplaceAllString(result, part)
+// NoUnderscores removes all Version.Value underscores and returns the result
+// string.
+func (v Version) NoUnderscores() (string, error) {
+	return v.replaceAll("NoUnderscores", `_`, "")
+}
+
+// NoDividers removes all Version.Value dividers (dots, hyphens and
+// underscores) and returns the result string.
+func (v Version) NoDividers() (string, error) {
+	return v.replaceAll("NoDividers", `[.\-_]`, "")
+}
+
+// BeforeHyphen extracts the Version.Value part before the first hyphen and
+// returns the result string.
+func (v Version) BeforeHyphen() (string, error) {
+	re := regexp.MustCompile(`^([^-]*)-`)
+	if re.MatchString(v.Value) {
+		return re.FindAllStringSubmatch(v.Value, -1)[0][1], nil
+	}
+	return "", fmt.Errorf(`version "%s": no BeforeHyphen() match`, v.Value)
+}
+
+// AfterHyphen extracts the Version.Value part after the first hyphen and
+// returns the result string.
+func (v Version) AfterHyphen() (string, error) {
+	re := regexp.MustCompile(`^[^-]*-(.*$)`)
+	if re.MatchString(v.Value) {
+		return re.FindAllStringSubmatch(v.Value, -1)[0][1], nil
+	}
+	return "", fmt.Errorf(`version "%s": no AfterHyphen() match`, v.Value)
+}
+
+// CSV splits the Version.Value by commas and returns the result slice.
+func (v Version) CSV() []string {
+	return v.Split(",")
+}
+
+// Split splits the Version.Value by the provided separator and returns the
+// result slice. If the separator is empty, the value is split by whitespaces
+// the same way as Ruby does.
+func (v Version) Split(separator string) []string {
+	if separator == "" {
+		return strings.Fields(v.Value)
 	}
 
+	return strings.Split(v.Value, separator)
+}
+
+// Chomp removes the provided suffix from the Version.Value if present and
+// returns the result string.
+func (v Version) Chomp(suffix string) string {
+	return strings.TrimSuffix(v.Value, suffix)
+}
+
+// Sub replaces the first match of the provided regular expression pattern in
+// the Version.Value with the replacement and returns the result string. The
+// replacement can reference the submatches using the "$1" syntax. Returns an
+// error if the pattern is invalid.
+func (v Version) Sub(pattern string, replacement string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	loc := re.FindStringSubmatchIndex(v.Value)
+	if loc == nil {
+		return v.Value, nil
+	}
+
+	result := re.ExpandString(nil, replacement, v.Value, loc)
+	return v.Value[:loc[0]] + string(result) + v.Value[loc[1]:], nil
+}
+
+// Gsub replaces all matches of the provided regular expression pattern in the
+// Version.Value with the replacement and returns the result string. The
+// replacement can reference the submatches using the "$1" syntax. Returns an
+// error if the pattern is invalid.
+func (v Version) Gsub(pattern string, replacement string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return re.ReplaceAllString(v.Value, replacement), nil
+}
+
+// replaceAll replaces all matches of the provided regular expression pattern in
+// the Version.Value with the replacement and returns the result string.
+// Returns an error, which includes the specified method name, if there are no
+// matches.
+func (v Version) replaceAll(method string, pattern string, replacement string) (string, error) {
+	re := regexp.MustCompile(pattern)
+	if re.MatchString(v.Value) {
+		return re.ReplaceAllString(v.Value, replacement), nil
+	}
+	return "", fmt.Errorf(`version "%s": no %s() match`, v.Value, method)
+}
+
+// versionMethods specify all supported version methods without arguments that
+// can be used in the "#{version.method}" interpolations.
+var versionMethods = map[string]func(Version) (string, error){
+	"major":                  Version.Major,
+	"minor":                  Version.Minor,
+	"patch":                  Version.Patch,
+	"major_minor":            Version.MajorMinor,
+	"major_minor_patch":      Version.MajorMinorPatch,
+	"before_comma":           Version.BeforeComma,
+	"after_comma":            Version.AfterComma,
+	"before_colon":           Version.BeforeColon,
+	"after_colon":            Version.AfterColon,
+	"before_hyphen":          Version.BeforeHyphen,
+	"after_hyphen":           Version.AfterHyphen,
+	"no_dots":                Version.NoDots,
+	"no_hyphens":             Version.NoHyphens,
+	"no_underscores":         Version.NoUnderscores,
+	"no_dividers":            Version.NoDividers,
+	"dots_to_underscores":    Version.DotsToUnderscores,
+	"dots_to_hyphens":        Version.DotsToHyphens,
+	"dots_to_slashes":        Version.DotsToSlashes,
+	"hyphens_to_dots":        Version.HyphensToDots,
+	"hyphens_to_underscores": Version.HyphensToUnderscores,
+	"underscores_to_dots":    Version.UnderscoresToDots,
+	"underscores_to_hyphens": Version.UnderscoresToHyphens,
+}
+
+// InterpolateIntoString interpolates existing version into the provided string
+// with Ruby interpolation syntax. All other interpolations are left unchanged.
+func (v Version) InterpolateIntoString(str string) (result string) {
+	result, _ = NewInterpolator(&v, nil).Interpolate(str)
 	return result
 }
 
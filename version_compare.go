@@ -0,0 +1,204 @@
+package cask
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// versionLatest specifies the Version.Value used for the ":latest" version.
+const versionLatest = "latest"
+
+// preReleaseTags specify the known pre-release tags ordered by their
+// precedence. A version containing one of these tags is considered older than
+// the same version without it.
+var preReleaseTags = map[string]int{
+	"dev":     0,
+	"alpha":   1,
+	"beta":    2,
+	"pre":     3,
+	"preview": 3,
+	"rc":      4,
+}
+
+// A versionToken represents a single numeric or alphabetic part of the
+// Version.Value used for comparison.
+type versionToken struct {
+	value     string
+	isNumeric bool
+}
+
+// isPreRelease checks whether the token is one of the known pre-release tags.
+func (t versionToken) isPreRelease() bool {
+	if t.isNumeric {
+		return false
+	}
+
+	_, ok := preReleaseTags[t.value]
+	return ok
+}
+
+// isZero checks whether the token is a numeric zero.
+func (t versionToken) isZero() bool {
+	return t.isNumeric && strings.TrimLeft(t.value, "0") == ""
+}
+
+// compare compares the token with another one and returns an integer
+// comparing them the same way as Version.Compare does.
+func (t versionToken) compare(other versionToken) int {
+	switch {
+	case t.isNumeric && other.isNumeric:
+		a, b := strings.TrimLeft(t.value, "0"), strings.TrimLeft(other.value, "0")
+		if len(a) != len(b) {
+			return compareInts(len(a), len(b))
+		}
+		return strings.Compare(a, b)
+	case t.isNumeric:
+		return 1
+	case other.isNumeric:
+		return -1
+	case t.isPreRelease() && other.isPreRelease():
+		return compareInts(preReleaseTags[t.value], preReleaseTags[other.value])
+	case t.isPreRelease():
+		return -1
+	case other.isPreRelease():
+		return 1
+	}
+
+	return strings.Compare(t.value, other.value)
+}
+
+// Compare compares the Version with another one and returns an integer
+// comparing them: 0 if both are equal, -1 if the Version is older and +1 if it's
+// newer.
+//
+// The values are compared the same way as Homebrew does: the numeric parts are
+// compared numerically, the known pre-release tags (alpha, beta, rc, etc.) are
+// older than the release itself and the comma-separated build suffix is only
+// compared when the versions before it are equal. The ":latest" version is
+// always considered newer than any other one.
+func (v Version) Compare(other Version) int {
+	if v.Value == other.Value {
+		return 0
+	}
+
+	if v.Value == versionLatest || other.Value == versionLatest {
+		if v.Value == versionLatest {
+			return 1
+		}
+		return -1
+	}
+
+	a, b := v.CSV(), other.CSV()
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y []versionToken
+		if i < len(a) {
+			x = tokenizeVersion(a[i])
+		}
+		if i < len(b) {
+			y = tokenizeVersion(b[i])
+		}
+
+		if result := compareVersionTokens(x, y); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// LessThan checks whether the Version is older than the other one.
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// SortVersions sorts the provided versions in the ascending order from the
+// oldest to the newest one. The order of equal versions is preserved.
+func SortVersions(versions []*Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].LessThan(*versions[j])
+	})
+}
+
+// tokenizeVersion splits the provided version string into numeric and
+// alphabetic tokens. All other characters are treated as dividers.
+func tokenizeVersion(value string) (tokens []versionToken) {
+	var current []rune
+	isNumeric := false
+
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, versionToken{string(current), isNumeric})
+			current = nil
+		}
+	}
+
+	for _, r := range strings.ToLower(value) {
+		switch {
+		case unicode.IsDigit(r):
+			if !isNumeric {
+				flush()
+			}
+			isNumeric = true
+			current = append(current, r)
+		case unicode.IsLetter(r):
+			if isNumeric {
+				flush()
+			}
+			isNumeric = false
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// compareVersionTokens compares two token slices. When one of the slices is
+// shorter, the missing tokens are considered equal to zeros, older than any
+// other remaining token, but newer than the pre-release tags.
+func compareVersionTokens(a []versionToken, b []versionToken) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var result int
+		switch {
+		case i >= len(a):
+			result = -compareMissingVersionToken(b[i])
+		case i >= len(b):
+			result = compareMissingVersionToken(a[i])
+		default:
+			result = a[i].compare(b[i])
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// compareMissingVersionToken compares the provided token with the missing one.
+func compareMissingVersionToken(t versionToken) int {
+	switch {
+	case t.isZero():
+		return 0
+	case t.isPreRelease():
+		return -1
+	}
+
+	return 1
+}
+
+// compareInts compares two integers and returns -1, 0 or +1.
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,102 @@
+package cask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		// equal
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0.0", "1.2", 0},
+		{"01.2", "1.2", 0},
+		{"1.2-3", "1.2.3", 0},
+		{"latest", "latest", 0},
+
+		// numeric
+		{"1.2.3", "1.2.4", -1},
+		{"1.10", "1.9", 1},
+		{"2.0", "1.99.99", 1},
+		{"1.2", "1.2.1", -1},
+		{"2019.1", "2018.12", 1},
+		{"12345678901234567890", "12345678901234567891", -1},
+
+		// build suffix
+		{"1.2.3,456", "1.2.3,457", -1},
+		{"1.2.3,456", "1.2.4,100", -1},
+		{"1.2.3,456", "1.2.3", 1},
+		{"1.2.3,a1b2", "1.2.3,a1b2", 0},
+		{"1.2.3,1000:400", "1.2.3,1000:401", -1},
+
+		// pre-release
+		{"2019.1-beta2", "2019.1", -1},
+		{"2019.1-beta2", "2019.1-beta10", -1},
+		{"2019.1-alpha", "2019.1-beta", -1},
+		{"2019.1-beta", "2019.1-rc1", -1},
+		{"2019.1-RC1", "2019.1-rc1", 0},
+		{"1.0-dev", "1.0-alpha", -1},
+		{"1.0-rc1", "1.0.1", -1},
+		{"1.0.1", "1.0-beta", 1},
+
+		// letters
+		{"1.0a", "1.0", 1},
+		{"1.0a", "1.0b", -1},
+		{"1.0a", "1.0.1", -1},
+
+		// latest
+		{"latest", "1.2.3", 1},
+		{"99.0", "latest", -1},
+	}
+
+	for _, testCase := range testCases {
+		a, b := NewVersion(testCase.a), NewVersion(testCase.b)
+		assert.Equal(t, testCase.expected, a.Compare(*b), "%s <=> %s", testCase.a, testCase.b)
+		assert.Equal(t, -testCase.expected, b.Compare(*a), "%s <=> %s", testCase.b, testCase.a)
+	}
+}
+
+func TestLessThan(t *testing.T) {
+	assert.True(t, NewVersion("1.2.3").LessThan(*NewVersion("1.2.4")))
+	assert.True(t, NewVersion("1.2.3-beta").LessThan(*NewVersion("1.2.3")))
+	assert.False(t, NewVersion("1.2.3").LessThan(*NewVersion("1.2.3")))
+	assert.False(t, NewVersion("latest").LessThan(*NewVersion("1.2.3")))
+}
+
+func TestSortVersions(t *testing.T) {
+	// preparations
+	versions := []*Version{
+		NewVersion("latest"),
+		NewVersion("1.10"),
+		NewVersion("1.2.3,457"),
+		NewVersion("1.2-rc1"),
+		NewVersion("1.2"),
+		NewVersion("1.2.3,456"),
+		NewVersion("1.2.0"),
+		NewVersion("1.2-beta"),
+	}
+
+	// test
+	SortVersions(versions)
+
+	var actual []string
+	for _, v := range versions {
+		actual = append(actual, v.Value)
+	}
+
+	assert.Equal(t, []string{
+		"1.2-beta",
+		"1.2-rc1",
+		"1.2",
+		"1.2.0",
+		"1.2.3,456",
+		"1.2.3,457",
+		"1.10",
+		"latest",
+	}, actual)
+}
@@ -51,10 +51,10 @@ func TestMajor(t *testing.T) {
 	assert.Nil(t, err)
 
 	// test (error)
-	actual, err = createTestVersionInvalid().Major()
+	actual, err = NewVersion(".invalid").Major()
 	assert.Empty(t, actual)
 	assert.Error(t, err)
-	assert.Equal(t, `version "invalid": no Major() match`, err.Error())
+	assert.Equal(t, `version ".invalid": no Major() match`, err.Error())
 }
 
 func TestMinor(t *testing.T) {
@@ -64,10 +64,10 @@ func TestMinor(t *testing.T) {
 	assert.Nil(t, err)
 
 	// test (error)
-	actual, err = createTestVersionInvalid().Minor()
+	actual, err = NewVersion(".invalid").Minor()
 	assert.Empty(t, actual)
 	assert.Error(t, err)
-	assert.Equal(t, `version "invalid": no Minor() match`, err.Error())
+	assert.Equal(t, `version ".invalid": no Minor() match`, err.Error())
 }
 
 func TestPatch(t *testing.T) {
@@ -77,10 +77,10 @@ func TestPatch(t *testing.T) {
 	assert.Nil(t, err)
 
 	// test (error)
-	actual, err = createTestVersionInvalid().Patch()
+	actual, err = NewVersion(".invalid").Patch()
 	assert.Empty(t, actual)
 	assert.Error(t, err)
-	assert.Equal(t, `version "invalid": no Patch() match`, err.Error())
+	assert.Equal(t, `version ".invalid": no Patch() match`, err.Error())
 }
 
 func TestMajorMinor(t *testing.T) {
@@ -90,10 +90,10 @@ func TestMajorMinor(t *testing.T) {
 	assert.Nil(t, err)
 
 	// test (error)
-	actual, err = createTestVersionInvalid().MajorMinor()
+	actual, err = NewVersion(".invalid").MajorMinor()
 	assert.Empty(t, actual)
 	assert.Error(t, err)
-	assert.Equal(t, `version "invalid": no MajorMinor() match`, err.Error())
+	assert.Equal(t, `version ".invalid": no MajorMinor() match`, err.Error())
 }
 
 func TestMajorMinorPatch(t *testing.T) {
@@ -103,10 +103,61 @@ func TestMajorMinorPatch(t *testing.T) {
 	assert.Nil(t, err)
 
 	// test (error)
-	actual, err = createTestVersionInvalid().MajorMinorPatch()
+	actual, err = NewVersion(".invalid").MajorMinorPatch()
 	assert.Empty(t, actual)
 	assert.Error(t, err)
-	assert.Equal(t, `version "invalid": no MajorMinorPatch() match`, err.Error())
+	assert.Equal(t, `version ".invalid": no MajorMinorPatch() match`, err.Error())
+}
+
+func TestParts(t *testing.T) {
+	testCases := map[string][]string{
+		"1.2.3,1000:400": {"1", "2", "3", "1000", "400"},
+		"12.10.3":        {"12", "10", "3"},
+		"2019.1-beta2":   {"2019", "1-beta2"},
+		"1.2_3":          {"1", "2_3"},
+		"1":              {"1"},
+		"1..2":           {"1"},
+		"1.2.":           {"1", "2"},
+		"v1.2.3":         {"v1", "2", "3"},
+		"beta.2":         {"beta", "2"},
+		"invalid":        {"invalid"},
+		".1":             nil,
+		"":               nil,
+	}
+
+	for value, expected := range testCases {
+		assert.Equal(t, expected, NewVersion(value).Parts(), value)
+	}
+}
+
+func TestSemanticVersionParts(t *testing.T) {
+	testCases := map[string][5]string{
+		// major, minor, patch, major_minor, major_minor_patch
+		"12.10.3":      {"12", "10", "3", "12.10", "12.10.3"},
+		"2019.1-beta2": {"2019", "1-beta2", "", "2019.1-beta2", "2019.1-beta2"},
+		"10.15,19A583": {"10", "15", "19A583", "10.15", "10.15.19A583"},
+		"1.2.3.4":      {"1", "2", "3", "1.2", "1.2.3"},
+		"5":            {"5", "", "", "5", "5"},
+		"v1.2.3":       {"v1", "2", "3", "v1.2", "v1.2.3"},
+		"beta.2":       {"beta", "2", "", "beta.2", "beta.2"},
+	}
+
+	for value, expected := range testCases {
+		v := NewVersion(value)
+		methods := []func() (string, error){
+			v.Major,
+			v.Minor,
+			v.Patch,
+			v.MajorMinor,
+			v.MajorMinorPatch,
+		}
+
+		for i, method := range methods {
+			actual, err := method()
+			assert.Equal(t, expected[i], actual, value)
+			assert.Equal(t, expected[i] == "", err != nil, value)
+		}
+	}
 }
 
 func TestBeforeComma(t *testing.T) {
@@ -227,6 +278,15 @@ func TestInterpolateIntoString(t *testing.T) {
 
 		// chained
 		"#{version.before_colon.before_comma.no_dots}": "123",
+		"#{version.after_comma.major}":                 "1000",
+
+		// with arguments
+		"#{version.csv.first}":               "1.2.3",
+		"#{version.before_colon.csv.second}": "1000",
+		"#{version.chomp(':400')}":           "1.2.3,1000",
+		`#{version.sub(/\./, '')}`:           "12.3,1000:400",
+		"#{version.split('.').first}":        "1",
+		"#{version.split(':').last.no_dots}": "400",
 
 		// when unknown method (shouldn't change at all)
 		"#{version.unknown}":                      "#{version.unknown}",
@@ -238,3 +298,107 @@ func TestInterpolateIntoString(t *testing.T) {
 		assert.Equal(t, interpolated, actual)
 	}
 }
+
+func TestDotsToSlashes(t *testing.T) {
+	// test
+	actual, err := createTestVersion().DotsToSlashes()
+	assert.Equal(t, "1/2/3,1000:400", actual)
+	assert.Nil(t, err)
+
+	// test (error)
+	actual, err = createTestVersionInvalid().DotsToSlashes()
+	assert.Empty(t, actual)
+	assert.Error(t, err)
+	assert.Equal(t, `version "invalid": no DotsToSlashes() match`, err.Error())
+}
+
+func TestHyphensAndUnderscores(t *testing.T) {
+	// preparations
+	v := NewVersion("1-2_3")
+
+	testCases := map[string]func() (string, error){
+		"1.2_3": v.HyphensToDots,
+		"1_2_3": v.HyphensToUnderscores,
+		"1-2.3": v.UnderscoresToDots,
+		"1-2-3": v.UnderscoresToHyphens,
+		"12_3":  v.NoHyphens,
+		"1-23":  v.NoUnderscores,
+		"123":   v.NoDividers,
+		"1":     v.BeforeHyphen,
+		"2_3":   v.AfterHyphen,
+	}
+
+	// test
+	for expected, fn := range testCases {
+		actual, err := fn()
+		assert.Equal(t, expected, actual)
+		assert.Nil(t, err)
+	}
+
+	// test (error)
+	testCasesErrors := map[string]func() (string, error){
+		"HyphensToDots":        createTestVersionInvalid().HyphensToDots,
+		"HyphensToUnderscores": createTestVersionInvalid().HyphensToUnderscores,
+		"UnderscoresToDots":    createTestVersionInvalid().UnderscoresToDots,
+		"UnderscoresToHyphens": createTestVersionInvalid().UnderscoresToHyphens,
+		"NoHyphens":            createTestVersionInvalid().NoHyphens,
+		"NoUnderscores":        createTestVersionInvalid().NoUnderscores,
+		"NoDividers":           createTestVersionInvalid().NoDividers,
+		"BeforeHyphen":         createTestVersionInvalid().BeforeHyphen,
+		"AfterHyphen":          createTestVersionInvalid().AfterHyphen,
+	}
+
+	for method, fn := range testCasesErrors {
+		actual, err := fn()
+		assert.Empty(t, actual)
+		assert.Error(t, err)
+		assert.Equal(t, `version "invalid": no `+method+`() match`, err.Error())
+	}
+}
+
+func TestCSV(t *testing.T) {
+	assert.Equal(t, []string{"1.2.3", "1000:400"}, createTestVersion().CSV())
+	assert.Equal(t, []string{"invalid"}, createTestVersionInvalid().CSV())
+}
+
+func TestSplit(t *testing.T) {
+	assert.Equal(t, []string{"1", "2", "3,1000:400"}, createTestVersion().Split("."))
+	assert.Equal(t, []string{"1.0", "beta"}, NewVersion(" 1.0  beta ").Split(""))
+}
+
+func TestChomp(t *testing.T) {
+	assert.Equal(t, "1.2.3,1000", createTestVersion().Chomp(":400"))
+	assert.Equal(t, "1.2.3,1000:400", createTestVersion().Chomp(".0"))
+}
+
+func TestSub(t *testing.T) {
+	// test
+	actual, err := createTestVersion().Sub(`\.`, "")
+	assert.Equal(t, "12.3,1000:400", actual)
+	assert.Nil(t, err)
+
+	actual, err = createTestVersion().Sub(`(\d+),(\d+)`, "$2,$1")
+	assert.Equal(t, "1.2.1000,3:400", actual)
+	assert.Nil(t, err)
+
+	actual, err = createTestVersion().Sub(`x`, "")
+	assert.Equal(t, "1.2.3,1000:400", actual)
+	assert.Nil(t, err)
+
+	// test (error)
+	actual, err = createTestVersion().Sub(`(`, "")
+	assert.Empty(t, actual)
+	assert.Error(t, err)
+}
+
+func TestGsub(t *testing.T) {
+	// test
+	actual, err := createTestVersion().Gsub(`\.`, "")
+	assert.Equal(t, "123,1000:400", actual)
+	assert.Nil(t, err)
+
+	// test (error)
+	actual, err = createTestVersion().Gsub(`(`, "")
+	assert.Empty(t, actual)
+	assert.Error(t, err)
+}
@@ -0,0 +1,492 @@
+package cask
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A FormattableStanza represents a custom Stanza that can be written back to
+// the cask by the Cask.Format. The custom stanzas that don't implement it are
+// omitted.
+type FormattableStanza interface {
+	Stanza
+
+	// Arguments returns the Ruby stanza arguments following the stanza name. For
+	// example, "true" for the "auto_updates true" stanza.
+	Arguments() string
+}
+
+// stanzaOrder specifies the canonical stanza order used by "brew style". Each
+// group is separated by an empty line.
+var stanzaOrder = [][]string{
+	{"version", "sha256"},
+	{"language"},
+	{"url", "appcast", "name", "desc", "homepage"},
+	{"livecheck"},
+	{"deprecate!", "disable!"},
+	{"auto_updates", "conflicts_with", "depends_on", "container"},
+	{
+		"suite",
+		"app",
+		"pkg",
+		"installer",
+		"binary",
+		"manpage",
+		"colorpicker",
+		"dictionary",
+		"font",
+		"input_method",
+		"internet_plugin",
+		"prefpane",
+		"qlplugin",
+		"mdimporter",
+		"screen_saver",
+		"service",
+		"audio_unit_plugin",
+		"vst_plugin",
+		"vst3_plugin",
+		"artifact",
+		"stage_only",
+	},
+	{"preflight"},
+	{"postflight"},
+	{"uninstall_preflight"},
+	{"uninstall_postflight"},
+	{"uninstall"},
+	{"zap"},
+	{"caveats"},
+}
+
+// StanzaOrder returns the canonical stanza order used by "brew style". The
+// stanza names are grouped, and each group should be separated by an empty line.
+func StanzaOrder() [][]string {
+	order := make([][]string, len(stanzaOrder))
+	for i, group := range stanzaOrder {
+		order[i] = append([]string(nil), group...)
+	}
+
+	return order
+}
+
+// A formattedStanza represents a single stanza written as a Ruby code line.
+type formattedStanza struct {
+	name string
+	line string
+}
+
+// A formattedStanzas represents all written stanzas of a single Variant in
+// the canonical order.
+type formattedStanzas []formattedStanza
+
+// A variantStanzas represents written stanzas of all cask variants.
+type variantStanzas []formattedStanzas
+
+// Format returns the Homebrew-Cask Ruby code of the Cask. The stanzas are
+// written in the canonical order used by "brew style". The stanzas that are
+// the same in all Cask.Variants are written once outside the "if" block,
+// which holds the rest of them. If all variants are the same, no "if" block is
+// written. Each branch has either the "MacOS.version" or the "Hardware::CPU"
+// condition, so the variants limited to both the macOS releases and the CPU
+// architecture result in an error.
+//
+// The stanza values are written as is, so they should contain Ruby escape
+// sequences where needed. Only the supported stanzas, the Cask.Lifecycle
+// stanzas and the custom stanzas implementing the FormattableStanza are
+// written.
+func (c *Cask) Format() (string, error) {
+	if c.Token == "" {
+		return "", errors.New("cask token is empty")
+	}
+
+	if len(c.Variants) == 0 {
+		return "", fmt.Errorf("cask '%s' has no variants", c.Token)
+	}
+
+	options := c.parseOptions()
+
+	variants := make(variantStanzas, len(c.Variants))
+	for i, v := range c.Variants {
+		variants[i] = formatVariant(v, c.Lifecycle.formatted()...)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "cask %s do\n", rubyString(c.Token))
+
+	// global stanzas are the ones which are the same in all variants
+	global := variants[0].globalNames(variants[1:])
+
+	if !variants.hasSpecific(global) {
+		writeStanzaGroups(&buf, variants[0], "  ")
+		buf.WriteString("end\n")
+
+		return buf.String(), nil
+	}
+
+	conditions := make([]string, len(c.Variants))
+	for i, v := range c.Variants {
+		condition, err := variantCondition(v, options)
+		if err != nil {
+			return "", fmt.Errorf("cask '%s' variant %s: %s", c.Token, v.label(), err)
+		}
+		conditions[i] = condition
+	}
+
+	var before, after formattedStanzas
+	for _, s := range variants[0] {
+		switch {
+		case !global[s.name]:
+			continue
+		case variants.isBeforeSpecific(s.name, global):
+			before = append(before, s)
+		default:
+			after = append(after, s)
+		}
+	}
+
+	if len(before) > 0 {
+		writeStanzaGroups(&buf, before, "  ")
+		buf.WriteString("\n")
+	}
+
+	for i, v := range c.Variants {
+		switch {
+		case i == 0:
+			fmt.Fprintf(&buf, "  if %s\n", conditions[i])
+		case i == len(c.Variants)-1 && isElseVariant(v, c.Variants[i-1], options):
+			buf.WriteString("  else\n")
+		default:
+			fmt.Fprintf(&buf, "  elsif %s\n", conditions[i])
+		}
+
+		var specific formattedStanzas
+		for _, s := range variants[i] {
+			if !global[s.name] {
+				specific = append(specific, s)
+			}
+		}
+
+		writeStanzaGroups(&buf, specific, "    ")
+	}
+
+	buf.WriteString("  end\n")
+
+	if len(after) > 0 {
+		buf.WriteString("\n")
+		writeStanzaGroups(&buf, after, "  ")
+	}
+
+	buf.WriteString("end\n")
+
+	return buf.String(), nil
+}
+
+// WriteTo writes the Cask.Format result to the provided io.Writer. It
+// implements the io.WriterTo interface.
+func (c *Cask) WriteTo(w io.Writer) (int64, error) {
+	content, err := c.Format()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.WriteString(w, content)
+	return int64(n), err
+}
+
+// formatVariant returns all Variant stanzas together with the provided extra
+// ones written as Ruby code lines in the canonical order.
+func formatVariant(v *Variant, extra ...formattedStanza) (result formattedStanzas) {
+	result = append(result, extra...)
+
+	if v.Version != nil {
+		value := rubyString(v.Version.Value)
+		if v.Version.Value == versionLatest {
+			value = ":latest"
+		}
+		result = append(result, formattedStanza{"version", "version " + value})
+	}
+
+	if v.SHA256 != nil {
+		value := rubyString(v.SHA256.Value)
+		if v.SHA256.Value == sha256NoCheck {
+			value = ":no_check"
+		}
+		result = append(result, formattedStanza{"sha256", "sha256 " + value})
+	}
+
+	if v.URL != nil {
+		result = append(result, formattedStanza{"url", "url " + rubyString(v.URL.Value)})
+	}
+
+	if v.Appcast != nil {
+		result = append(result, formattedStanza{"appcast", "appcast " + rubyString(v.Appcast.URL)})
+	}
+
+	if v.Livecheck != nil {
+		result = append(result, formattedStanza{"livecheck", "livecheck " + v.Livecheck.Arguments()})
+	}
+
+	for _, n := range v.Names {
+		result = append(result, formattedStanza{"name", "name " + rubyString(n.Value)})
+	}
+
+	if v.Homepage != nil {
+		result = append(result, formattedStanza{"homepage", "homepage " + rubyString(v.Homepage.Value)})
+	}
+
+	for _, a := range v.Artifacts {
+		line := a.Type.String() + " " + rubyString(a.Value)
+		if a.Target != "" {
+			line += ", target: " + rubyString(a.Target)
+		}
+		if a.AllowUntrusted {
+			line += ", allow_untrusted: true"
+		}
+		result = append(result, formattedStanza{a.Type.String(), line})
+	}
+
+	for name, stanzas := range v.Stanzas {
+		for _, s := range stanzas {
+			if f, ok := s.(FormattableStanza); ok {
+				result = append(result, formattedStanza{name, name + " " + f.Arguments()})
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := stanzaRank(result[i].name), stanzaRank(result[j].name)
+		if a != b {
+			return a < b
+		}
+		return a == len(stanzaIndex) && result[i].name < result[j].name
+	})
+
+	return result
+}
+
+// lines returns the written lines of the stanzas with the specified name.
+func (f formattedStanzas) lines(name string) (lines []string) {
+	for _, s := range f {
+		if s.name == name {
+			lines = append(lines, s.line)
+		}
+	}
+
+	return lines
+}
+
+// globalNames returns the names of the stanzas that are written the same way
+// in all other variants.
+func (f formattedStanzas) globalNames(others []formattedStanzas) map[string]bool {
+	global := make(map[string]bool)
+
+	for _, s := range f {
+		if _, ok := global[s.name]; ok {
+			continue
+		}
+
+		lines := strings.Join(f.lines(s.name), "\n")
+		global[s.name] = true
+
+		for _, other := range others {
+			if strings.Join(other.lines(s.name), "\n") != lines {
+				global[s.name] = false
+				break
+			}
+		}
+	}
+
+	return global
+}
+
+// hasSpecific checks whether at least one of the variants has stanzas that
+// aren't global.
+func (v variantStanzas) hasSpecific(global map[string]bool) bool {
+	for _, variant := range v {
+		for _, s := range variant {
+			if !global[s.name] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isBeforeSpecific checks whether the stanza with the specified name goes
+// before all variant specific stanzas in the canonical order.
+func (v variantStanzas) isBeforeSpecific(name string, global map[string]bool) bool {
+	rank := stanzaRank(name)
+
+	for _, variant := range v {
+		for _, s := range variant {
+			if !global[s.name] && stanzaRank(s.name) <= rank {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// stanzaIndex specifies the stanzaOrder position of each known stanza name.
+var stanzaIndex = func() map[string]int {
+	index := make(map[string]int)
+	for _, group := range stanzaOrder {
+		for _, name := range group {
+			index[name] = len(index)
+		}
+	}
+
+	return index
+}()
+
+// stanzaGroup specifies the stanzaOrder group of each known stanza name.
+var stanzaGroup = func() map[string]int {
+	groups := make(map[string]int)
+	for i, group := range stanzaOrder {
+		for _, name := range group {
+			groups[name] = i
+		}
+	}
+
+	return groups
+}()
+
+// stanzaRank returns the canonical position of the stanza with the specified
+// name. The unknown stanzas go after all known ones.
+func stanzaRank(name string) int {
+	if i, ok := stanzaIndex[name]; ok {
+		return i
+	}
+
+	return len(stanzaIndex)
+}
+
+// sameStanzaGroup checks whether both stanza names belong to the same
+// stanzaOrder group. Each unknown stanza has its own group.
+func sameStanzaGroup(a string, b string) bool {
+	x, okX := stanzaGroup[a]
+	y, okY := stanzaGroup[b]
+
+	if !okX || !okY {
+		return a == b
+	}
+
+	return x == y
+}
+
+// writeStanzaGroups writes the stanzas with the specified indentation and
+// separates the stanza groups by empty lines. Each line of the multiline
+// stanzas is indented except the empty ones.
+func writeStanzaGroups(buf *bytes.Buffer, stanzas formattedStanzas, indent string) {
+	for i, s := range stanzas {
+		if i > 0 && !sameStanzaGroup(stanzas[i-1].name, s.name) {
+			buf.WriteString("\n")
+		}
+
+		for _, line := range strings.Split(s.line, "\n") {
+			if line != "" {
+				buf.WriteString(indent + line)
+			}
+			buf.WriteString("\n")
+		}
+	}
+}
+
+// isLatestVariant checks whether the Variant supports only the latest targeted
+// macOS release, which is the case for the "else" branch.
+func isLatestVariant(v *Variant, options *ParseOptions) bool {
+	latest := options.LatestMacOS()
+	return v.MinimumSupportedMacOS == latest && v.MaximumSupportedMacOS == latest
+}
+
+// isElseVariant checks whether the last Variant can be written as the "else"
+// branch following the previous one. Like in the Parser, the "else" branch
+// supports only the latest targeted macOS release and the opposite
+// architecture of the previous "Hardware::CPU" condition.
+func isElseVariant(v *Variant, previous *Variant, options *ParseOptions) bool {
+	return isLatestVariant(v, options) && v.Arch == previous.Arch.Opposite()
+}
+
+// variantCondition returns the "Hardware::CPU" condition matching the
+// Variant.Arch or the "MacOS.version" condition for the variants supporting
+// all architectures. An error is returned if the Variant is limited to both.
+func variantCondition(v *Variant, options *ParseOptions) (string, error) {
+	if v.Arch == ArchAll {
+		return macOSCondition(v, options), nil
+	}
+
+	if !isLatestVariant(v, options) {
+		return "", errors.New("both macOS and CPU architecture conditions are not supported")
+	}
+
+	return "Hardware::CPU." + v.Arch.Symbol() + "?", nil
+}
+
+// macOSCondition returns the "MacOS.version" condition matching the Variant
+// supported macOS releases.
+func macOSCondition(v *Variant, options *ParseOptions) string {
+	min, max := v.MinimumSupportedMacOS, v.MaximumSupportedMacOS
+
+	switch {
+	case min == options.OldestMacOS():
+		return "MacOS.version <= :" + max.Symbol()
+	case max == options.LatestMacOS():
+		return "MacOS.version >= :" + min.Symbol()
+	case min == max:
+		return "MacOS.version == :" + min.Symbol()
+	}
+
+	return fmt.Sprintf("MacOS.version >= :%s && MacOS.version <= :%s", min.Symbol(), max.Symbol())
+}
+
+// rubyString returns the provided value as a Ruby string literal. The single
+// quotes are used unless the value has interpolations, escape sequences or
+// single quotes.
+func rubyString(value string) string {
+	if !strings.ContainsAny(value, `'\`) && !strings.Contains(value, "#{") {
+		return "'" + value + "'"
+	}
+
+	return rubyDoubleQuotedString(value)
+}
+
+// rubyDoubleQuotedString returns the provided value as a double-quoted Ruby
+// string literal. The unescaped double quotes outside the interpolations are
+// escaped.
+func rubyDoubleQuotedString(value string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+
+	locations := findInterpolations(value)
+	for i := 0; i < len(value); i++ {
+		if len(locations) > 0 && i == locations[0][0] {
+			buf.WriteString(value[i:locations[0][1]])
+			i = locations[0][1] - 1
+			locations = locations[1:]
+			continue
+		}
+
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			buf.WriteString(value[i : i+2])
+			i++
+			continue
+		case value[i] == '\\':
+			buf.WriteString(`\\`)
+			continue
+		case value[i] == '"':
+			buf.WriteByte('\\')
+		}
+
+		buf.WriteByte(value[i])
+	}
+
+	buf.WriteByte('"')
+
+	return buf.String()
+}
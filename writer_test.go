@@ -0,0 +1,275 @@
+package cask
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A testDependsOn represents the custom "depends_on" stanza used in tests.
+type testDependsOn struct {
+	BaseStanza
+
+	// Value specifies the stanza value.
+	Value string
+}
+
+// String returns a string representation of the testDependsOn struct.
+func (d testDependsOn) String() string {
+	return d.Value
+}
+
+// Arguments returns the testDependsOn Ruby stanza arguments.
+func (d testDependsOn) Arguments() string {
+	return "macos: " + rubyString(d.Value)
+}
+
+// A testFailingWriter represents the io.Writer which always fails.
+type testFailingWriter struct{}
+
+// Write returns an error.
+func (w testFailingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestFormat(t *testing.T) {
+	files, err := ioutil.ReadDir(filepath.Join(getWorkingDir(), testdataPath, "format"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		// preparations
+		c := NewCask(string(getTestdata(file.Name())))
+		assert.Nil(t, c.Parse(), file.Name())
+
+		// test
+		actual, err := c.Format()
+		assert.Nil(t, err, file.Name())
+		assert.Equal(t, string(getTestdata(filepath.Join("format", file.Name()))), actual, file.Name())
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	files, err := ioutil.ReadDir(filepath.Join(getWorkingDir(), testdataPath))
+	assert.Nil(t, err)
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		// preparations
+		c := NewCask(string(getTestdata(file.Name())))
+		if c.Parse() != nil {
+			continue
+		}
+
+		formatted, err := c.Format()
+		assert.Nil(t, err, file.Name())
+
+		actual := NewCask(formatted)
+		assert.Nil(t, actual.Parse(), file.Name())
+
+		// test
+		assert.Equal(t, c.Token, actual.Token, file.Name())
+		assert.Len(t, actual.Variants, len(c.Variants), file.Name())
+		for i, v := range actual.Variants {
+			expected := c.Variants[i]
+			assert.Equal(t, expected.MinimumSupportedMacOS, v.MinimumSupportedMacOS, file.Name())
+			assert.Equal(t, expected.MaximumSupportedMacOS, v.MaximumSupportedMacOS, file.Name())
+			assert.Equal(t, expected.Arch, v.Arch, file.Name())
+			assert.Equal(t, expected.GetVersion().Value, v.GetVersion().Value, file.Name())
+			assert.Equal(t, expected.GetSHA256().Value, v.GetSHA256().Value, file.Name())
+			assert.Equal(t, expected.GetURL().Value, v.GetURL().Value, file.Name())
+			assert.Equal(t, expected.GetAppcast().URL, v.GetAppcast().URL, file.Name())
+			assert.Len(t, v.GetNames(), len(expected.GetNames()), file.Name())
+			for j, name := range v.GetNames() {
+				assert.Equal(t, expected.GetNames()[j].Value, name.Value, file.Name())
+			}
+			assert.Equal(t, expected.GetHomepage().Value, v.GetHomepage().Value, file.Name())
+			assert.Equal(t, expected.GetArtifacts(), v.GetArtifacts(), file.Name())
+		}
+
+		formattedAgain, err := actual.Format()
+		assert.Nil(t, err, file.Name())
+		assert.Equal(t, formatted, formattedAgain, file.Name())
+	}
+}
+
+func TestFormatVariants(t *testing.T) {
+	// preparations
+	c := NewCask("")
+	c.Token = "example"
+
+	v1 := NewVariant()
+	v1.MinimumSupportedMacOS = MacOSYosemite
+	v1.MaximumSupportedMacOS = MacOSElCapitan
+	v1.Version = NewVersion("1.0.0")
+	v1.SHA256 = NewSHA256("no_check")
+	v1.AddArtifact(NewArtifact(ArtifactBinary, "bin/example"))
+	v1.AddArtifact(NewArtifact(ArtifactApp, "Example.app"))
+	v1.AddStanza("depends_on", &testDependsOn{Value: ">= :yosemite"})
+	v1.AddStanza("unsupported", NewName("unsupported"))
+	c.AddVariant(v1)
+
+	v2 := NewVariant()
+	v2.MinimumSupportedMacOS = MacOSSierra
+	v2.MaximumSupportedMacOS = MacOSHighSierra
+	v2.Version = NewVersion("2.0.0")
+	v2.AddArtifact(NewArtifact(ArtifactBinary, "bin/example"))
+	v2.AddArtifact(NewArtifact(ArtifactApp, "Example.app"))
+	v2.AddStanza("depends_on", &testDependsOn{Value: ">= :yosemite"})
+	c.AddVariant(v2)
+
+	// test
+	actual, err := c.Format()
+	assert.Nil(t, err)
+	assert.Equal(t, `cask 'example' do
+  if MacOS.version >= :yosemite && MacOS.version <= :el_capitan
+    version '1.0.0'
+    sha256 :no_check
+  elsif MacOS.version >= :sierra
+    version '2.0.0'
+  end
+
+  depends_on macos: '>= :yosemite'
+
+  app 'Example.app'
+  binary 'bin/example'
+end
+`, actual)
+
+	// test (parsing the formatted cask)
+	parsed := NewCask(actual)
+	assert.Nil(t, parsed.Parse())
+	if assert.Len(t, parsed.Variants, 2) {
+		assert.Equal(t, MacOSYosemite, parsed.Variants[0].MinimumSupportedMacOS)
+		assert.Equal(t, MacOSElCapitan, parsed.Variants[0].MaximumSupportedMacOS)
+		assert.Equal(t, "no_check", parsed.Variants[0].GetSHA256().Value)
+		assert.Equal(t, MacOSSierra, parsed.Variants[1].MinimumSupportedMacOS)
+		assert.Equal(t, MacOSHighSierra, parsed.Variants[1].MaximumSupportedMacOS)
+	}
+}
+
+func TestFormatArch(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("if-global-version-last.rb")))
+	assert.Nil(t, c.Parse())
+
+	arm := c.Variants[1].clone()
+	arm.Arch = ArchARM
+	arm.URL = NewURL("https://example.com/app_#{version}_#{arch}.dmg")
+	c.Variants[1].Arch = ArchIntel
+	c.AddVariant(arm)
+
+	// test
+	actual, err := c.Format()
+	assert.Nil(t, err)
+	assert.Contains(t, actual, `  if MacOS.version <= :leopard
+    sha256 'cd9d7b8c5d48e2d7f0673e0aa13e82e198f66e958d173d679e38a94abb1b2435'
+
+    url "https://example.com/app_#{version}_mac32.dmg"
+  elsif Hardware::CPU.intel?
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+
+    url "https://example.com/app_#{version}_mac64.dmg"
+  else
+    sha256 '9065ae8493fa73bfdf5d29ffcd0012cd343475cf3d550ae526407b9910eb35b7'
+
+    url "https://example.com/app_#{version}_#{arch}.dmg"
+  end
+`)
+
+	// test (parsing the formatted cask)
+	parsed := NewCask(actual)
+	assert.Nil(t, parsed.Parse())
+	if assert.Len(t, parsed.Variants, 3) {
+		assert.Equal(t, ArchAll, parsed.Variants[0].Arch)
+		assert.Equal(t, MacOSLeopard, parsed.Variants[0].MaximumSupportedMacOS)
+		assert.Equal(t, ArchIntel, parsed.Variants[1].Arch)
+		assert.Equal(t, ArchARM, parsed.Variants[2].Arch)
+		assert.Equal(t, MacOSHighSierra, parsed.Variants[2].MinimumSupportedMacOS)
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	// preparations
+	c := NewCask("")
+
+	// test
+	actual, err := c.Format()
+	assert.Empty(t, actual)
+	assert.EqualError(t, err, "cask token is empty")
+
+	c.Token = "example"
+	actual, err = c.Format()
+	assert.Empty(t, actual)
+	assert.EqualError(t, err, "cask 'example' has no variants")
+
+	c = NewCask(string(getTestdata("if-global-version-last.rb")))
+	assert.Nil(t, c.Parse())
+	c.Variants[0].Arch = ArchIntel
+
+	actual, err = c.Format()
+	assert.Empty(t, actual)
+	assert.EqualError(
+		t,
+		err,
+		"cask 'if-global-version-last' variant 10.4-10.5 (intel): both macOS and CPU architecture conditions are not supported",
+	)
+}
+
+func TestWriteTo(t *testing.T) {
+	// preparations
+	c := NewCask(string(getTestdata("latest.rb")))
+	assert.Nil(t, c.Parse())
+
+	var buf bytes.Buffer
+
+	// test
+	n, err := c.WriteTo(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, string(getTestdata("format/latest.rb")), buf.String())
+	assert.Equal(t, int64(buf.Len()), n)
+
+	// test (error)
+	n, err = c.WriteTo(testFailingWriter{})
+	assert.EqualError(t, err, "write error")
+	assert.Equal(t, int64(0), n)
+
+	n, err = NewCask("").WriteTo(&buf)
+	assert.EqualError(t, err, "cask token is empty")
+	assert.Equal(t, int64(0), n)
+}
+
+func TestRubyString(t *testing.T) {
+	testCases := map[string]string{
+		`example`:                       `'example'`,
+		`#{version}`:                    `"#{version}"`,
+		`it's`:                          `"it's"`,
+		`say "hi" #{version}`:           `"say \"hi\" #{version}"`,
+		`already \"escaped\"`:           `"already \"escaped\""`,
+		`#{version.sub(/\./, "")}.dmg"`: `"#{version.sub(/\./, "")}.dmg\""`,
+		`trailing \`:                    `"trailing \\"`,
+	}
+
+	for value, expected := range testCases {
+		assert.Equal(t, expected, rubyString(value), value)
+	}
+}
+
+func TestStanzaOrder(t *testing.T) {
+	// preparations
+	order := StanzaOrder()
+
+	// test
+	assert.Equal(t, []string{"version", "sha256"}, order[0])
+	assert.Equal(t, []string{"caveats"}, order[len(order)-1])
+
+	order[0][0] = "changed"
+	assert.Equal(t, "version", StanzaOrder()[0][0])
+}